@@ -0,0 +1,370 @@
+package ginc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// Validatable is implemented by requests with rules that struct tags cannot express.
+// Returning a *core.ValidationError keeps its fields in the response.
+type Validatable interface {
+	Validate() error
+}
+
+// Bind binds the path parameters (uri tags), the query (form tags), the headers (header tags)
+// and the JSON or form body (see MethodHasBody) into a new T, then runs the binding tags and the Validate method.
+// Errors are *core.ValidationError listing each invalid field, rendered as 400 by
+// middleware.ErrorHandler:
+//
+//	type UpdateTaskRequest struct {
+//		ID     string `uri:"id" binding:"required"`
+//		Tenant string `header:"X-Tenant-ID" binding:"required"`
+//		Title  string `json:"title" binding:"required,max=255"`
+//	}
+//
+//	req, err := ginc.Bind[UpdateTaskRequest](c)
+//	if err != nil {
+//		_ = c.Error(err)
+//		return
+//	}
+func Bind[T any](c *gin.Context) (*T, error) {
+	obj := new(T)
+	if err := bindRequest(c, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// MustBind is Bind writing the error response and aborting, it returns false when it did.
+func MustBind[T any](c *gin.Context) (*T, bool) {
+	obj, err := Bind[T](c)
+	if err == nil {
+		return obj, true
+	}
+
+	ec := mwutil.ErrorContext()
+
+	var (
+		ve          *core.ValidationError
+		maxBytesErr *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &ve):
+		core.WriteStandardErrorResponse(c, http.StatusBadRequest, ec.ValidationError(ve.Message, ve.Fields))
+	case errors.As(err, &maxBytesErr):
+		core.WriteStandardErrorResponse(c, http.StatusRequestEntityTooLarge, ec.CustomError(
+			"REQUEST_ENTITY_TOO_LARGE", "", core.ErrRequestEntityTooLarge.Error(), err.Error(),
+		))
+	default:
+		core.WriteStandardErrorResponse(c, http.StatusInternalServerError, ec.InternalServerError(
+			core.ErrInternalServerError.Error(), err.Error(),
+		))
+	}
+	c.Abort()
+	return nil, false
+}
+
+func bindRequest(c *gin.Context, obj any) error {
+	if len(c.Params) > 0 {
+		params := make(map[string][]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = []string{p.Value}
+		}
+		if err := binding.MapFormWithTag(obj, params, "uri"); err != nil {
+			return mappingError("path", err)
+		}
+	}
+
+	if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+		return mappingError("query", err)
+	}
+
+	if err := binding.MapFormWithTag(obj, headerValues(obj, c.Request.Header), "header"); err != nil {
+		return mappingError("header", err)
+	}
+
+	if err := bindBody(c, obj); err != nil {
+		return err
+	}
+
+	if err := validateValue(reflect.ValueOf(obj)); err != nil {
+		return toValidationError(err)
+	}
+
+	if v, ok := obj.(Validatable); ok {
+		if err := v.Validate(); err != nil {
+			var ve *core.ValidationError
+			if errors.As(err, &ve) {
+				return ve
+			}
+			return &core.ValidationError{Message: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// MethodHasBody reports whether Bind reads the body of a request with the method, every method
+// but GET and HEAD. The openapi package documents a request body for the same methods.
+func MethodHasBody(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead
+}
+
+func bindBody(c *gin.Context, obj any) error {
+	req := c.Request
+	if !MethodHasBody(req.Method) || req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil
+	}
+
+	switch c.ContentType() {
+	case binding.MIMEPOSTForm, binding.MIMEMultipartPOSTForm:
+		if err := req.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return bodyError(err)
+		}
+		if err := binding.MapFormWithTag(obj, req.PostForm, "form"); err != nil {
+			return mappingError("body", err)
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(req.Body)
+	if binding.EnableDecoderUseNumber {
+		dec.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(obj); err != nil && !errors.Is(err, io.EOF) {
+		return bodyError(err)
+	}
+	return nil
+}
+
+// bodyError converts a decoding error, a *http.MaxBytesError is kept for the 413 of ErrorHandler.
+func bodyError(err error) error {
+	var (
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return err
+	case errors.As(err, &typeErr):
+		return core.NewValidationError(core.FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: "must be a " + jsonTypeName(typeErr.Type),
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return core.NewValidationError(core.FieldError{Field: field, Rule: "unknown", Message: "is not allowed"})
+	}
+
+	return &core.ValidationError{Message: "The request body is malformed: " + err.Error()}
+}
+
+// mappingError converts a path, query or header parsing error, gin does not tell the field.
+func mappingError(source string, err error) error {
+	return &core.ValidationError{Message: "The request " + source + " is malformed: " + err.Error()}
+}
+
+func toValidationError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return &core.ValidationError{Message: err.Error()}
+	}
+
+	fields := make([]core.FieldError, len(verrs))
+	for i, fe := range verrs {
+		fields[i] = core.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: ruleMessage(fe),
+		}
+	}
+	return core.NewValidationError(fields...)
+}
+
+// fieldPath returns the namespace of the field with the request names, without the struct name.
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, path, ok := strings.Cut(ns, "."); ok {
+		return path
+	}
+	return ns
+}
+
+// Validator returns the validator of Bind, it runs the binding tags like gin's one but reports the
+// json, uri, form or header name of the fields. Register custom rules on it before serving, ex:
+// ginc.Validator().RegisterValidation("slug", isSlug). gin's binding.Validator is left unchanged.
+func Validator() *validator.Validate {
+	return bindValidator()
+}
+
+// bindValidator is created on first use, the tag name function is set before any validation.
+var bindValidator = sync.OnceValue(func() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "uri", "form", "header"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+	return v
+})
+
+// validateValue validates a struct, a pointer to a struct or each element of a slice, like gin's validator.
+func validateValue(value reflect.Value) error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		if value.Elem().Kind() == reflect.Struct {
+			return bindValidator().Struct(value.Interface())
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return bindValidator().Struct(value.Interface())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := validateValue(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ruleMessage returns a readable message of the common rules.
+func ruleMessage(fe validator.FieldError) string {
+	p := fe.Param()
+	isString := fe.Kind() == reflect.String
+	isList := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Array || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(p), ", ")
+	case "len":
+		if isString {
+			return "must be " + p + " characters long"
+		}
+		return "must contain " + p + " items"
+	case "min", "gte":
+		if isString {
+			return "must be at least " + p + " characters long"
+		}
+		if isList {
+			return "must contain at least " + p + " items"
+		}
+		return "must be greater than or equal to " + p
+	case "max", "lte":
+		if isString {
+			return "must be at most " + p + " characters long"
+		}
+		if isList {
+			return "must contain at most " + p + " items"
+		}
+		return "must be less than or equal to " + p
+	case "gt":
+		return "must be greater than " + p
+	case "lt":
+		return "must be less than " + p
+	case "eq":
+		return "must be equal to " + p
+	case "ne":
+		return "must not be equal to " + p
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "numeric", "number":
+		return "must be a number"
+	case "datetime":
+		return "must be a datetime in the format " + p
+	}
+
+	if p != "" {
+		return "failed on the " + fe.Tag() + "=" + p + " rule"
+	}
+	return "failed on the " + fe.Tag() + " rule"
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// headerValues collects the headers named by the header tags of obj, header names are case-insensitive.
+func headerValues(obj any, h http.Header) map[string][]string {
+	values := make(map[string][]string)
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if name, _, _ := strings.Cut(f.Tag.Get("header"), ","); name != "" && name != "-" {
+				if v := h.Values(name); len(v) > 0 {
+					values[name] = v
+				}
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct || (f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct) {
+				walk(f.Type)
+			}
+		}
+	}
+	walk(reflect.TypeOf(obj))
+
+	return values
+}
@@ -0,0 +1,81 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+const defaultMaxBodySize = "10MB"
+
+// bodyLimitConfig holds the request body flags, see middleware.BodyLimitConfig.
+type bodyLimitConfig struct {
+	maxBodySize  string
+	routes       string
+	isDecompress bool
+}
+
+func (bc *bodyLimitConfig) initFlags(prefix string) {
+	flag.StringVar(&bc.maxBodySize, prefix+"-max-body-size", defaultMaxBodySize, "largest request body, in bytes or with a KB, MB, GB suffix, 0 to disable. Default 10MB")
+	flag.StringVar(&bc.routes, prefix+"-max-body-size-routes", "", "comma-separated body limits per route prefix, ex: POST /v1/uploads=100MB,/v1/import=0")
+	flag.BoolVar(&bc.isDecompress, prefix+"-decompress-enabled", true, "decompress gzip encoded request bodies, the body limit applies to the decompressed size. Default true")
+}
+
+// middlewares builds the decompression and body limit middlewares from flags.
+func (bc *bodyLimitConfig) middlewares() ([]gin.HandlerFunc, error) {
+	limit, err := parseByteSize(bc.maxBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max body size: %w", err)
+	}
+
+	routes := make(map[string]int64)
+	for _, item := range splitList(bc.routes) {
+		route, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid max body size route %q, expected route=size", item)
+		}
+
+		size, err := parseByteSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max body size route %q: %w", item, err)
+		}
+		routes[strings.TrimSpace(route)] = size
+	}
+
+	var handlers []gin.HandlerFunc
+	if bc.isDecompress {
+		handlers = append(handlers, middleware.Decompress())
+	}
+	if limit > 0 || len(routes) > 0 {
+		handlers = append(handlers, middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{Limit: limit, Routes: routes}))
+	}
+
+	return handlers, nil
+}
+
+// parseByteSize parses sizes like 1024, 512KB, 10MB or 1GB.
+func parseByteSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		value  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if v, ok := strings.CutSuffix(s, unit.suffix); ok {
+			s, multiplier = strings.TrimSpace(v), unit.value
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return n * multiplier, nil
+}
@@ -0,0 +1,46 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+// compressConfig holds the response compression flags, see middleware.CompressConfig.
+type compressConfig struct {
+	isEnabled    bool
+	encodings    string
+	minSize      string
+	contentTypes string
+}
+
+func (cc *compressConfig) initFlags(prefix string) {
+	flag.BoolVar(&cc.isEnabled, prefix+"-compression-enabled", false, "compress responses negotiated with Accept-Encoding. Default false")
+	flag.StringVar(&cc.encodings, prefix+"-compression-encodings", "zstd,br,gzip", "comma-separated encodings in order of preference (zstd, br, gzip)")
+	flag.StringVar(&cc.minSize, prefix+"-compression-min-size", "1KB", "smallest response compressed, in bytes or with a KB, MB suffix. Default 1KB")
+	flag.StringVar(&cc.contentTypes, prefix+"-compression-types", strings.Join(middleware.DefaultCompressContentTypes, ","), "comma-separated content type prefixes compressed")
+}
+
+// middleware builds the compression middleware from flags.
+func (cc *compressConfig) middleware() (gin.HandlerFunc, error) {
+	minSize, err := parseByteSize(cc.minSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compression min size: %w", err)
+	}
+
+	encodings := splitList(cc.encodings)
+	for _, e := range encodings {
+		if e != middleware.EncodingZstd && e != middleware.EncodingBrotli && e != middleware.EncodingGzip {
+			return nil, fmt.Errorf("unsupported compression encoding %q", e)
+		}
+	}
+
+	return middleware.CompressWithConfig(middleware.CompressConfig{
+		Encodings:    encodings,
+		MinSize:      int(minSize),
+		ContentTypes: splitList(cc.contentTypes),
+	}), nil
+}
@@ -0,0 +1,65 @@
+package ginc
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+// corsConfig holds the CORS flags, see middleware.CORSConfig.
+type corsConfig struct {
+	isEnabled          bool
+	allowOrigins       string
+	allowOriginRegexps string
+	allowCredentials   bool
+	allowHeaders       string
+	exposeHeaders      string
+	allowMethods       string
+	maxAge             time.Duration
+}
+
+func (cc *corsConfig) initFlags(prefix string) {
+	def := middleware.DefaultCORSConfig()
+
+	flag.BoolVar(&cc.isEnabled, prefix+"-cors-enabled", false, "enable CORS middleware. Default false")
+	flag.StringVar(&cc.allowOrigins, prefix+"-cors-allow-origins", strings.Join(def.AllowOrigins, ","), "comma-separated allowed origins: exact (https://example.com), wildcard subdomain (https://*.example.com) or *. Default *")
+	flag.StringVar(&cc.allowOriginRegexps, prefix+"-cors-allow-origin-regexps", "", "comma-separated regular expressions matched against the whole origin")
+	flag.BoolVar(&cc.allowCredentials, prefix+"-cors-allow-credentials", false, "allow cookies and authorization headers, not allowed with the * origin. Default false")
+	flag.StringVar(&cc.allowHeaders, prefix+"-cors-allow-headers", strings.Join(def.AllowHeaders, ","), "comma-separated request headers allowed in cross-origin requests")
+	flag.StringVar(&cc.exposeHeaders, prefix+"-cors-expose-headers", "", "comma-separated response headers readable by the client")
+	flag.StringVar(&cc.allowMethods, prefix+"-cors-allow-methods", strings.Join(def.AllowMethods, ","), "comma-separated methods allowed in cross-origin requests")
+	flag.DurationVar(&cc.maxAge, prefix+"-cors-max-age", def.MaxAge, "how long browsers cache preflight responses, 0 to disable. Default 1h")
+}
+
+// middleware builds the CORS middleware from flags.
+func (cc *corsConfig) middleware() (gin.HandlerFunc, error) {
+	cfg := middleware.CORSConfig{
+		AllowOrigins:       splitList(cc.allowOrigins),
+		AllowOriginRegexps: splitList(cc.allowOriginRegexps),
+		AllowCredentials:   cc.allowCredentials,
+		AllowHeaders:       splitList(cc.allowHeaders),
+		ExposeHeaders:      splitList(cc.exposeHeaders),
+		AllowMethods:       splitList(cc.allowMethods),
+		MaxAge:             cc.maxAge,
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return middleware.CORSWithConfig(cfg), nil
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
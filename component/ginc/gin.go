@@ -2,20 +2,85 @@ package ginc
 
 import (
 	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+	"github.com/taimaifika/service-context/component/ginc/stream"
 )
 
 const (
 	defaultPort = 3000
 	defaultMode = "debug"
+
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = http.DefaultMaxHeaderBytes
+	defaultShutdownTimeout   = 15 * time.Second
+	defaultDrainDelay        = 0
+	defaultTLSReloadInterval = time.Minute
+
+	defaultLivezPath    = "/livez"
+	defaultReadyzPath   = "/readyz"
+	defaultVersionPath  = "/version"
+	defaultPprofPath    = "/debug/pprof"
+	defaultReadyTimeout = 3 * time.Second
 )
 
 type Config struct {
 	port    int
 	ginMode string
+
+	// http.Server timeouts
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+
+	// graceful shutdown deadline
+	shutdownTimeout time.Duration
+	// time to keep serving after readiness fails, before shutdown starts
+	drainDelay time.Duration
+
+	// TLS
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsReloadInterval time.Duration
+
+	// Serve HTTP/2 without TLS (h2c)
+	isH2C bool
+
+	// Operational routes
+	adminPort     int
+	isLivez       bool
+	livezPath     string
+	isReadyz      bool
+	readyzPath    string
+	readyzTimeout time.Duration
+	isVersion     bool
+	versionPath   string
+	isPprof       bool
+	pprofPath     string
+
+	routes    routesConfig
+	tracing   tracingConfig
+	metrics   metricsConfig
+	security  securityHeadersConfig
+	cors      corsConfig
+	shedding  loadSheddingConfig
+	timeout   timeoutConfig
+	bodyLimit bodyLimitConfig
+	compress  compressConfig
 }
 
 type ginEngine struct {
@@ -23,12 +88,33 @@ type ginEngine struct {
 	name   string
 	id     string
 	router *gin.Engine
+	sv     sctx.ServiceContext
+
+	// adminRouter serves operational routes when an admin port is configured
+	adminRouter *gin.Engine
+
+	server      *http.Server
+	adminServer *http.Server
+
+	// modules added with AddRoutes, registered with the discovered ones on Start
+	registrars       []RouteRegistrar
+	routesRegistered bool
+
+	// draining is set when Stop is called, readiness fails from then on
+	draining atomic.Bool
+	// shutdownCh is closed when the server stops accepting connections
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+	// streams tracks the SSE and WebSocket connections, closed with shutdownCh
+	streams *stream.Tracker
 }
 
 func NewGin(id string) *ginEngine {
 	return &ginEngine{
-		Config: new(Config),
-		id:     id,
+		Config:     new(Config),
+		id:         id,
+		shutdownCh: make(chan struct{}),
+		streams:    stream.NewTracker(),
 	}
 }
 
@@ -38,6 +124,11 @@ func (gs *ginEngine) ID() string {
 
 func (gs *ginEngine) Activate(sv sctx.ServiceContext) error {
 	gs.name = sv.GetName()
+	gs.sv = sv
+
+	if (gs.tlsCertFile == "") != (gs.tlsKeyFile == "") {
+		return fmt.Errorf("-%s-tls-cert-file and -%s-tls-key-file must be set together", gs.id, gs.id)
+	}
 
 	if gs.ginMode == "release" {
 		gin.SetMode(gin.ReleaseMode)
@@ -45,17 +136,138 @@ func (gs *ginEngine) Activate(sv sctx.ServiceContext) error {
 
 	slog.Info("init engine...")
 	gs.router = gin.New()
+	// *gin.Context used as context.Context falls back to the request context,
+	// so values like the request ID are visible to slog.InfoContext(c, ...)
+	gs.router.ContextWithFallback = true
+
+	// first, so the span covers the other middlewares and is in the context of the user ones
+	if gs.tracing.isEnabled {
+		opsPaths := []string{gs.livezPath, gs.readyzPath, gs.versionPath, gs.metrics.path, strings.TrimSuffix(gs.pprofPath, "/") + "/*"}
+		gs.router.Use(gs.tracing.middleware(gs.name, opsPaths))
+	}
+
+	// before the other middlewares, so the responses they write, ex: timeout, carry the request ID
+	gs.router.Use(middleware.RequestID())
+
+	// before the other middlewares, so their responses are measured
+	if gs.metrics.isEnabled {
+		gs.router.Use(gs.metrics.middleware([]string{gs.livezPath, gs.readyzPath, gs.metrics.path}))
+	}
+
+	// before CORS, so preflight and error responses get the headers too
+	if gs.security.isEnabled() {
+		security, err := gs.security.middleware()
+		if err != nil {
+			return err
+		}
+		gs.router.Use(security)
+	}
+
+	if gs.cors.isEnabled {
+		cors, err := gs.cors.middleware()
+		if err != nil {
+			return err
+		}
+		gs.router.Use(cors)
+	}
+
+	if gs.compress.isEnabled {
+		compress, err := gs.compress.middleware()
+		if err != nil {
+			return err
+		}
+		gs.router.Use(compress)
+	}
+
+	bodyLimit, err := gs.bodyLimit.middlewares()
+	if err != nil {
+		return err
+	}
+	gs.router.Use(bodyLimit...)
+
+	// operational routes go to a dedicated router when served on the admin port
+	opsRouter := gs.router
+	if gs.adminPort > 0 {
+		gs.adminRouter = gin.New()
+		opsRouter = gs.adminRouter
+	}
+	gs.registerOpsRoutes(opsRouter)
+
+	// installed after the operational routes, probes are never shed
+	if gs.shedding.isEnabled {
+		shedding, err := gs.shedding.middleware()
+		if err != nil {
+			return err
+		}
+		gs.router.Use(shedding)
+	}
+
+	// installed after the operational routes, pprof profiles run longer than a request timeout
+	if gs.timeout.isEnabled() {
+		timeout, err := gs.timeout.middleware()
+		if err != nil {
+			return err
+		}
+		gs.router.Use(timeout)
+	}
 
 	return nil
 }
 
+// Stop drains the server for rolling updates: readiness fails first, the server keeps
+// serving during the drain delay, then it stops accepting new connections and waits
+// for in-flight requests within the shutdown timeout.
+// The service context stops components in reverse registration order, register gin after the
+// datastores its handlers use, otherwise they are closed while requests are still drained.
 func (gs *ginEngine) Stop() error {
-	return nil
+	gs.drain()
+	return gs.shutdown()
 }
 
 func (gs *ginEngine) InitFlags() {
-	flag.IntVar(&gs.Config.port, "gin-port", defaultPort, "gin server port. Default 3000")
-	flag.StringVar(&gs.Config.ginMode, "gin-mode", defaultMode, "gin mode (debug | release). Default debug")
+	flag.IntVar(&gs.Config.port, gs.id+"-port", defaultPort, "gin server port. Default 3000")
+	flag.StringVar(&gs.Config.ginMode, gs.id+"-mode", defaultMode, "gin mode (debug | release). Default debug")
+
+	// Server timeouts
+	flag.DurationVar(&gs.Config.readTimeout, gs.id+"-read-timeout", defaultReadTimeout, "maximum duration for reading the entire request, including the body. Default 30s")
+	flag.DurationVar(&gs.Config.readHeaderTimeout, gs.id+"-read-header-timeout", defaultReadHeaderTimeout, "maximum duration for reading request headers. Default 10s")
+	flag.DurationVar(&gs.Config.writeTimeout, gs.id+"-write-timeout", defaultWriteTimeout, "maximum duration before timing out writes of the response. Default 30s")
+	flag.DurationVar(&gs.Config.idleTimeout, gs.id+"-idle-timeout", defaultIdleTimeout, "maximum time to wait for the next request when keep-alives are enabled. Default 120s")
+	flag.IntVar(&gs.Config.maxHeaderBytes, gs.id+"-max-header-bytes", defaultMaxHeaderBytes, "maximum number of bytes the server will read parsing the request headers. Default 1048576")
+	flag.DurationVar(&gs.Config.shutdownTimeout, gs.id+"-shutdown-timeout", defaultShutdownTimeout, "maximum duration to wait for in-flight requests on shutdown. Default 15s")
+	flag.DurationVar(&gs.Config.drainDelay, gs.id+"-drain-delay", defaultDrainDelay, "time to keep serving after readiness starts failing on stop, should cover the load balancer deregistration (e.g. 10s on Kubernetes). Default 0s")
+
+	// TLS
+	flag.StringVar(&gs.Config.tlsCertFile, gs.id+"-tls-cert-file", "", "path to the TLS certificate file, TLS is enabled when both cert and key are set")
+	flag.StringVar(&gs.Config.tlsKeyFile, gs.id+"-tls-key-file", "", "path to the TLS private key file")
+	flag.DurationVar(&gs.Config.tlsReloadInterval, gs.id+"-tls-reload-interval", defaultTLSReloadInterval, "how often the TLS cert/key files are checked for changes. Default 1m")
+
+	// HTTP/2
+	flag.BoolVar(&gs.Config.isH2C, gs.id+"-h2c", false, "serve HTTP/2 over cleartext (h2c) when TLS is disabled. Default false")
+
+	// Operational routes
+	flag.IntVar(&gs.Config.adminPort, gs.id+"-admin-port", 0, "serve operational routes (health, version, pprof) on this port instead of the main port, 0 to disable. Default 0")
+	flag.BoolVar(&gs.Config.isLivez, gs.id+"-livez-enabled", true, "enable liveness route. Default true")
+	flag.StringVar(&gs.Config.livezPath, gs.id+"-livez-path", defaultLivezPath, "liveness route path. Default /livez")
+	flag.BoolVar(&gs.Config.isReadyz, gs.id+"-readyz-enabled", true, "enable readiness route backed by components health checks. Default true")
+	flag.StringVar(&gs.Config.readyzPath, gs.id+"-readyz-path", defaultReadyzPath, "readiness route path. Default /readyz")
+	flag.DurationVar(&gs.Config.readyzTimeout, gs.id+"-readyz-timeout", defaultReadyTimeout, "timeout of components health checks in readiness route. Default 3s")
+	flag.BoolVar(&gs.Config.isVersion, gs.id+"-version-enabled", false, "enable build info route. Default false")
+	flag.StringVar(&gs.Config.versionPath, gs.id+"-version-path", defaultVersionPath, "build info route path. Default /version")
+	flag.BoolVar(&gs.Config.isPprof, gs.id+"-pprof-enabled", false, "enable net/http/pprof routes. Default false")
+	flag.StringVar(&gs.Config.pprofPath, gs.id+"-pprof-path", defaultPprofPath, "pprof routes path prefix. Default /debug/pprof")
+
+	gs.Config.routes.initFlags(gs.id)
+
+	// Middlewares
+	gs.Config.tracing.initFlags(gs.id)
+	gs.Config.metrics.initFlags(gs.id)
+	gs.Config.security.initFlags(gs.id)
+	gs.Config.cors.initFlags(gs.id)
+	gs.Config.shedding.initFlags(gs.id)
+	gs.Config.timeout.initFlags(gs.id)
+	gs.Config.bodyLimit.initFlags(gs.id)
+	gs.Config.compress.initFlags(gs.id)
 }
 
 func (gs *ginEngine) GetPort() int {
@@ -65,3 +277,32 @@ func (gs *ginEngine) GetPort() int {
 func (gs *ginEngine) GetRouter() *gin.Engine {
 	return gs.router
 }
+
+// GetAdminRouter returns the router of the admin port, it is nil when the admin port is disabled.
+func (gs *ginEngine) GetAdminRouter() *gin.Engine {
+	return gs.adminRouter
+}
+
+// IsDraining reports whether the server is draining and should not receive new traffic.
+func (gs *ginEngine) IsDraining() bool {
+	return gs.draining.Load()
+}
+
+// ShutdownNotify returns a channel closed when the server stops accepting new connections.
+// Long-lived handlers (SSE, WebSocket) should watch it and close their connections,
+// since the server does not wait for hijacked or streaming connections on its own.
+// The handlers of the stream package do it when given the Streams tracker.
+func (gs *ginEngine) ShutdownNotify() <-chan struct{} {
+	return gs.shutdownCh
+}
+
+// Streams returns the tracker of the SSE and WebSocket connections, ex: stream.SSEConfig{Tracker: gin.Streams()}.
+// They are closed when the server shuts down, which waits for them within the shutdown timeout.
+func (gs *ginEngine) Streams() *stream.Tracker {
+	return gs.streams
+}
+
+// GetServer returns the underlying http.Server, it is nil until Start is called.
+func (gs *ginEngine) GetServer() *http.Server {
+	return gs.server
+}
@@ -0,0 +1,73 @@
+package ginc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/core"
+)
+
+// stubServiceContext provides what Activate reads, the other methods are not called.
+type stubServiceContext struct {
+	sctx.ServiceContext
+}
+
+func (stubServiceContext) GetName() string                { return "test" }
+func (stubServiceContext) Get(string) (interface{}, bool) { return nil, false }
+
+func TestTimeoutResponseHasRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	gs := NewGin("gin")
+	gs.bodyLimit.maxBodySize = "0"
+	gs.timeout = timeoutConfig{timeout: 20 * time.Millisecond, statusCode: http.StatusGatewayTimeout}
+	if err := gs.Activate(stubServiceContext{}); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+
+	gs.router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	tests := []struct {
+		name      string
+		requestID string
+	}{
+		{"client request ID", "client-id-123"},
+		{"generated request ID", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+			if tt.requestID != "" {
+				req.Header.Set(core.HeaderRequestID, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			gs.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusGatewayTimeout {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusGatewayTimeout)
+			}
+
+			rid := w.Header().Get(core.HeaderRequestID)
+			if rid == "" || tt.requestID != "" && rid != tt.requestID {
+				t.Fatalf("%s header = %q, want %q", core.HeaderRequestID, rid, tt.requestID)
+			}
+
+			var resp core.StandardResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if resp.Error == nil || resp.Error.RequestID != rid {
+				t.Fatalf("body request_id does not match header %q: %s", rid, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+const (
+	loadSheddingGradient = "gradient"
+	loadSheddingAIMD     = "aimd"
+	loadSheddingFixed    = "fixed"
+)
+
+// loadSheddingConfig holds the load shedding flags, see middleware.LoadSheddingConfig.
+type loadSheddingConfig struct {
+	isEnabled        bool
+	algorithm        string
+	initialLimit     int
+	minLimit         int
+	maxLimit         int
+	latencyThreshold time.Duration
+	routes           string
+	retryAfter       time.Duration
+}
+
+func (lc *loadSheddingConfig) initFlags(prefix string) {
+	flag.BoolVar(&lc.isEnabled, prefix+"-load-shedding-enabled", false, "reject requests with 503 once the concurrency limit is reached. Default false")
+	flag.StringVar(&lc.algorithm, prefix+"-load-shedding-algorithm", loadSheddingGradient, "concurrency limit algorithm (gradient | aimd | fixed), fixed uses the initial limit. Default gradient")
+	flag.IntVar(&lc.initialLimit, prefix+"-load-shedding-initial-limit", 20, "concurrency limit before any latency sample. Default 20")
+	flag.IntVar(&lc.minLimit, prefix+"-load-shedding-min-limit", 5, "lowest adaptive concurrency limit. Default 5")
+	flag.IntVar(&lc.maxLimit, prefix+"-load-shedding-max-limit", 1000, "highest adaptive concurrency limit. Default 1000")
+	flag.DurationVar(&lc.latencyThreshold, prefix+"-load-shedding-latency-threshold", time.Second, "latency over which the aimd limit decreases. Default 1s")
+	flag.StringVar(&lc.routes, prefix+"-load-shedding-routes", "", "comma-separated priorities per route prefix (low | normal | high | critical), critical is never shed, ex: /v1/reports=low,POST /v1/checkout=high,/v1/events=critical")
+	flag.DurationVar(&lc.retryAfter, prefix+"-load-shedding-retry-after", time.Second, "Retry-After advised to rejected clients. Default 1s")
+}
+
+// middleware builds the load shedding middleware from flags.
+func (lc *loadSheddingConfig) middleware() (gin.HandlerFunc, error) {
+	bounds := middleware.LimitBounds{Initial: lc.initialLimit, Min: lc.minLimit, Max: lc.maxLimit}
+	if bounds.Min > bounds.Max {
+		return nil, fmt.Errorf("load shedding min limit %d is greater than max limit %d", bounds.Min, bounds.Max)
+	}
+
+	var limit middleware.LimitAlgorithm
+	switch lc.algorithm {
+	case loadSheddingGradient:
+		limit = middleware.NewGradientLimit(middleware.GradientLimitConfig{LimitBounds: bounds})
+	case loadSheddingAIMD:
+		limit = middleware.NewAIMDLimit(middleware.AIMDLimitConfig{LimitBounds: bounds, LatencyThreshold: lc.latencyThreshold})
+	case loadSheddingFixed:
+		if lc.initialLimit < 1 {
+			return nil, fmt.Errorf("load shedding initial limit must be positive, got %d", lc.initialLimit)
+		}
+		limit = middleware.NewFixedLimit(lc.initialLimit)
+	default:
+		return nil, fmt.Errorf("unknown load shedding algorithm %q, expected gradient, aimd or fixed", lc.algorithm)
+	}
+
+	routes := make(map[string]middleware.Priority)
+	for _, item := range splitList(lc.routes) {
+		route, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid load shedding route %q, expected route=priority", item)
+		}
+
+		p, err := middleware.ParsePriority(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid load shedding route %q: %w", item, err)
+		}
+		routes[strings.TrimSpace(route)] = p
+	}
+
+	return middleware.LoadSheddingWithConfig(middleware.LoadSheddingConfig{
+		Limit:      limit,
+		Routes:     routes,
+		RetryAfter: lc.retryAfter,
+	}), nil
+}
@@ -0,0 +1,37 @@
+package ginc
+
+import (
+	"flag"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+const (
+	defaultMetricsPath      = "/metrics"
+	defaultMetricsComponent = "otel"
+)
+
+// MetricsHandlerProvider is implemented by the component exposing the metrics in the Prometheus format, ex: otelc.
+type MetricsHandlerProvider interface {
+	MetricsHandler() http.Handler
+}
+
+// metricsConfig holds the HTTP metrics flags, see middleware.MetricsConfig.
+type metricsConfig struct {
+	isEnabled bool
+	path      string
+	component string
+}
+
+func (mc *metricsConfig) initFlags(prefix string) {
+	flag.BoolVar(&mc.isEnabled, prefix+"-metrics-enabled", false, "record request count, errors, latency and in-flight requests with the global meter provider. Default false")
+	flag.StringVar(&mc.path, prefix+"-metrics-path", defaultMetricsPath, "Prometheus scrape route, served when the metrics component exposes a handler. Default /metrics")
+	flag.StringVar(&mc.component, prefix+"-metrics-component", defaultMetricsComponent, "ID of the component exposing the Prometheus handler (otelc with the Prometheus exporter enabled). Default otel")
+}
+
+// middleware builds the metrics middleware, the operational routes are not measured.
+func (mc *metricsConfig) middleware(skipPaths []string) gin.HandlerFunc {
+	return middleware.MetricsWithConfig(middleware.MetricsConfig{SkipPaths: skipPaths})
+}
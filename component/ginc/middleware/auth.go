@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+var ErrMissingToken = errors.New("missing access token")
+
+// TokenParser validates a token and returns its claims, it is implemented by the jwtc component.
+type TokenParser interface {
+	ParseToken(ctx context.Context, tokenString string) (*jwt.RegisteredClaims, error)
+}
+
+// AuthConfig configures the authentication middleware.
+type AuthConfig struct {
+	// Parser validates the token, usually the jwtc component.
+	Parser TokenParser
+	// Optional lets requests without a token through anonymously.
+	// A token that is present but not valid is always rejected.
+	Optional bool
+	// CookieName, when set, is used to read the token if the Authorization header is missing.
+	CookieName string
+}
+
+// RequireAuth rejects requests without a valid bearer token with 401.
+func RequireAuth(parser TokenParser) gin.HandlerFunc {
+	return AuthWithConfig(AuthConfig{Parser: parser})
+}
+
+// OptionalAuth sets the requester when a valid bearer token is sent and lets anonymous requests through.
+func OptionalAuth(parser TokenParser) gin.HandlerFunc {
+	return AuthWithConfig(AuthConfig{Parser: parser, Optional: true})
+}
+
+// AuthWithConfig validates the bearer token of the request and builds a core.Requester
+// from the "sub" and "jti" claims. The requester is stored in the gin context
+// (c.Get("requester")) and in the request context (core.GetRequester), the subject is set
+// on the active span as enduser.id.
+func AuthWithConfig(cfg AuthConfig) gin.HandlerFunc {
+	if cfg.Parser == nil {
+		panic("auth: token parser is required")
+	}
+
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		token := extractToken(c, cfg.CookieName)
+		if token == "" {
+			if cfg.Optional {
+				c.Next()
+				return
+			}
+
+			writeUnauthorized(c, ec, ErrMissingToken)
+			return
+		}
+
+		claims, err := cfg.Parser.ParseToken(c.Request.Context(), token)
+		if err != nil {
+			writeUnauthorized(c, ec, err)
+			return
+		}
+
+		requester := core.NewRequester(claims.Subject, claims.ID)
+
+		c.Set(string(core.KeyRequester), requester)
+		c.Request = c.Request.WithContext(core.ContextWithRequester(c.Request.Context(), requester))
+
+		if span := trace.SpanFromContext(c.Request.Context()); span.IsRecording() {
+			span.SetAttributes(attribute.String("enduser.id", requester.GetSubject()))
+		}
+
+		c.Next()
+	}
+}
+
+// extractToken reads the token from "Authorization: Bearer <token>", then from the cookie.
+func extractToken(c *gin.Context, cookieName string) string {
+	if scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	if cookieName != "" {
+		if token, err := c.Cookie(cookieName); err == nil {
+			return token
+		}
+	}
+
+	return ""
+}
+
+func writeUnauthorized(c *gin.Context, ec *core.ErrorContext, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	core.WriteStandardErrorResponse(c, http.StatusUnauthorized, ec.UnauthorizedError(core.ErrUnauthorized.Error(), err.Error()))
+	c.Abort()
+}
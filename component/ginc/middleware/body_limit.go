@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// BodyLimitConfig configures the request body limit middleware.
+type BodyLimitConfig struct {
+	// Limit is the largest request body in bytes, 0 for none.
+	Limit int64
+	// Routes overrides the limit for the route templates starting with a prefix, keyed by
+	// "METHOD /prefix" or "/prefix", ex: "POST /v1/uploads". The longest prefix wins, 0 disables the limit.
+	Routes map[string]int64
+}
+
+// BodyLimit rejects request bodies larger than limit bytes.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return BodyLimitWithConfig(BodyLimitConfig{Limit: limit})
+}
+
+// BodyLimitWithConfig rejects requests whose Content-Length is over the limit with 413,
+// and stops reading bodies of unknown length at the limit: the read fails with *http.MaxBytesError,
+// rendered as 413 by ErrorHandler. Use it after Decompress so the decompressed size is limited.
+func BodyLimitWithConfig(cfg BodyLimitConfig) gin.HandlerFunc {
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		limit := cfg.Limit
+		if l, ok := routePrefixValue(c, cfg.Routes); ok {
+			limit = l
+		}
+
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			core.WriteStandardErrorResponse(c, http.StatusRequestEntityTooLarge, ec.CustomError(
+				mwutil.ErrorCode(http.StatusRequestEntityTooLarge),
+				"",
+				core.ErrRequestEntityTooLarge.Error(),
+				"",
+			))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// Decompress transparently decompresses gzip encoded request bodies, other encodings are rejected with 415.
+func Decompress() gin.HandlerFunc {
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if encoding != "gzip" && encoding != "x-gzip" {
+			core.WriteStandardErrorResponse(c, http.StatusUnsupportedMediaType, ec.CustomError(
+				mwutil.ErrorCode(http.StatusUnsupportedMediaType),
+				"",
+				"The request content encoding is not supported",
+				"unsupported Content-Encoding "+encoding,
+			))
+			c.Abort()
+			return
+		}
+
+		zr, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			core.WriteStandardErrorResponse(c, http.StatusBadRequest, ec.BadRequestError(
+				"The request body is not valid gzip",
+				err.Error(),
+			))
+			c.Abort()
+			return
+		}
+		defer zr.Close()
+
+		c.Request.Body = readCloser{Reader: zr, Closer: c.Request.Body}
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+
+		c.Next()
+	}
+}
@@ -0,0 +1,389 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/core"
+)
+
+const (
+	// HeaderCache tells whether a response was served from the cache (HIT) or not (MISS).
+	HeaderCache = "X-Cache"
+
+	defaultCacheTTL         = time.Minute
+	defaultCacheMaxBodySize = 1 << 20 // 1MB
+	defaultCacheKeyPrefix   = "httpcache"
+
+	cachePathTagPrefix = "path:"
+)
+
+var errCacheHijack = errors.New("cache: hijacking is not supported on cached routes")
+
+// CacheEntry is a cached response.
+type CacheEntry struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body,omitempty"`
+	ETag     string      `json:"etag"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// CacheStore keeps the cached responses, see NewMemoryCacheStore and NewRedisCacheStore.
+type CacheStore interface {
+	// Get returns the entry of key, nil when it is missing or expired.
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	// Set stores the entry of key for ttl, the entry is removed when one of its tags is deleted.
+	Set(ctx context.Context, key string, entry CacheEntry, tags []string, ttl time.Duration) error
+	// Delete removes entries by key.
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteTags removes the entries of the tags.
+	DeleteTags(ctx context.Context, tags ...string) error
+}
+
+// CacheConfig configures the response cache of a route.
+type CacheConfig struct {
+	// TTL is how long a response is cached. Default 1m.
+	TTL time.Duration
+	// MaxAge is the Cache-Control max-age sent to clients. Default TTL, a negative value sends no-cache
+	// so clients always revalidate with If-None-Match.
+	MaxAge time.Duration
+	// VaryHeaders are request headers selecting a different response, ex: Accept-Language.
+	VaryHeaders []string
+	// VaryByRequester caches a response per core.Requester, responses are marked private.
+	// Without it, responses are shared by every client: only cache routes that are not user specific.
+	VaryByRequester bool
+	// Tags returns the tags of the response, to invalidate it with ResponseCache.InvalidateTags.
+	Tags func(c *gin.Context) []string
+	// MaxBodySize is the largest response cached. Default 1MB.
+	MaxBodySize int
+}
+
+// ResponseCache caches GET responses in a store shared by the routes it is used on,
+// so writes can invalidate them.
+//
+//	cache := middleware.NewResponseCache(middleware.NewRedisCacheStore(redisComp.GetRedis()))
+//	router.GET("/tasks/:id", cache.Cache(middleware.CacheConfig{
+//		TTL:  time.Minute,
+//		Tags: func(c *gin.Context) []string { return []string{"task:" + c.Param("id")} },
+//	}), getTaskHdl)
+//
+//	// after updating the task
+//	cache.InvalidateTags(ctx, "task:"+id)
+type ResponseCache struct {
+	store  CacheStore
+	prefix string
+}
+
+// NewResponseCache creates a response cache backed by store, nil for an in-memory store.
+func NewResponseCache(store CacheStore) *ResponseCache {
+	if store == nil {
+		store = NewMemoryCacheStore()
+	}
+	return &ResponseCache{store: store, prefix: defaultCacheKeyPrefix}
+}
+
+// InvalidatePaths removes the cached responses of URL paths, whatever their query and vary headers.
+func (rc *ResponseCache) InvalidatePaths(ctx context.Context, paths ...string) error {
+	tags := make([]string, len(paths))
+	for i, p := range paths {
+		tags[i] = cachePathTagPrefix + p
+	}
+	return rc.InvalidateTags(ctx, tags...)
+}
+
+// InvalidateTags removes the cached responses tagged with one of the tags.
+func (rc *ResponseCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(tags))
+	for i, t := range tags {
+		prefixed[i] = rc.prefix + ":tag:" + t
+	}
+	return rc.store.DeleteTags(ctx, prefixed...)
+}
+
+// Cache caches the 200 responses of GET requests by normalized URL, vary headers and requester,
+// HEAD requests are served from the GET entries but never stored as they have no body.
+// Cached responses have a strong ETag, If-None-Match is answered with 304.
+// Handlers can opt out of a response with "Cache-Control: no-store".
+// When the store fails, the request is handled without cache and the error is logged.
+func (rc *ResponseCache) Cache(cfg CacheConfig) gin.HandlerFunc {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultCacheTTL
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = cfg.TTL
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultCacheMaxBodySize
+	}
+
+	varyHeaders := make([]string, len(cfg.VaryHeaders))
+	for i, h := range cfg.VaryHeaders {
+		varyHeaders[i] = http.CanonicalHeaderKey(h)
+	}
+	cfg.VaryHeaders = varyHeaders
+
+	cacheControl := "public, max-age=" + strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	if cfg.VaryByRequester {
+		cacheControl = "private, max-age=" + strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	if cfg.MaxAge < 0 {
+		cacheControl = "no-cache"
+	}
+
+	vary := strings.Join(cfg.VaryHeaders, ", ")
+	if cfg.VaryByRequester {
+		vary = strings.TrimPrefix(vary+", Authorization", ", ")
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := rc.cacheKey(c, cfg)
+
+		// "Cache-Control: no-cache" from the client refreshes the entry
+		if !strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			entry, err := rc.store.Get(ctx, key)
+			if err != nil {
+				slog.ErrorContext(ctx, "cache store error", "error", err)
+			}
+			if entry != nil {
+				serveCached(c, entry)
+				return
+			}
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, header: c.Writer.Header(), status: http.StatusOK, limit: cfg.MaxBodySize}
+		c.Writer = w
+
+		c.Next()
+
+		c.Writer = w.ResponseWriter
+
+		if w.overflow {
+			return
+		}
+
+		if c.Request.Method == http.MethodHead || w.status != http.StatusOK || !w.written || strings.Contains(w.header.Get("Cache-Control"), "no-store") {
+			w.flush(false)
+			return
+		}
+
+		entry := CacheEntry{
+			Status:   w.status,
+			Body:     w.body.Bytes(),
+			ETag:     strongETag(w.body.Bytes()),
+			StoredAt: time.Now(),
+		}
+
+		header := w.header
+		header.Set("ETag", entry.ETag)
+		if header.Get("Cache-Control") == "" {
+			header.Set("Cache-Control", cacheControl)
+		}
+		if vary != "" {
+			header.Set("Vary", vary)
+		}
+
+		entry.Header = header.Clone()
+		for _, h := range replaySkipHeaders {
+			entry.Header.Del(h)
+		}
+
+		tags := []string{rc.prefix + ":tag:" + cachePathTagPrefix + c.Request.URL.Path}
+		if cfg.Tags != nil {
+			for _, t := range cfg.Tags(c) {
+				tags = append(tags, rc.prefix+":tag:"+t)
+			}
+		}
+
+		if err := rc.store.Set(context.WithoutCancel(ctx), key, entry, tags, cfg.TTL); err != nil {
+			slog.ErrorContext(ctx, "cache store error", "error", err)
+		}
+
+		header.Set(HeaderCache, "MISS")
+		w.flush(etagMatch(c.GetHeader("If-None-Match"), entry.ETag))
+	}
+}
+
+// cacheKey hashes the normalized URL (sorted query), the vary headers and the requester.
+func (rc *ResponseCache) cacheKey(c *gin.Context, cfg CacheConfig) string {
+	var b strings.Builder
+	b.WriteString(c.Request.URL.Path)
+	b.WriteString("?")
+	b.WriteString(c.Request.URL.Query().Encode())
+
+	for _, h := range cfg.VaryHeaders {
+		b.WriteString("\n" + h + ":" + c.GetHeader(h))
+	}
+
+	if cfg.VaryByRequester {
+		b.WriteString("\nrequester:")
+		if r := core.GetRequester(c.Request.Context()); r != nil {
+			b.WriteString(r.GetSubject())
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return rc.prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+func serveCached(c *gin.Context, entry *CacheEntry) {
+	header := c.Writer.Header()
+	for k, v := range entry.Header {
+		header[k] = v
+	}
+	header.Set(HeaderCache, "HIT")
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+
+	if etagMatch(c.GetHeader("If-None-Match"), entry.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+	} else {
+		c.Status(entry.Status)
+		if c.Request.Method == http.MethodHead {
+			c.Writer.WriteHeaderNow()
+		} else {
+			_, _ = c.Writer.Write(entry.Body)
+		}
+	}
+	c.Abort()
+}
+
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatch reports whether an If-None-Match header matches etag, weak comparison as required for GET.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds the response until the handlers return, up to limit bytes.
+// Larger responses are written through.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	limit    int
+	written  bool
+	overflow bool
+}
+
+// flush writes the buffered response, without body when notModified.
+func (w *bufferedWriter) flush(notModified bool) {
+	if notModified {
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	} else {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.overflow {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !w.written && code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	if w.overflow {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if !w.overflow && w.body.Len()+len(b) > w.limit {
+		w.overflow = true
+		w.flush(false)
+	}
+	if w.overflow {
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	if w.overflow {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.overflow {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written || w.overflow
+}
+
+// Flush writes the buffered response through, the response is not cached.
+func (w *bufferedWriter) Flush() {
+	if !w.overflow {
+		w.overflow = true
+		w.flush(false)
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errCacheHijack
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheSweepInterval is how often expired entries are removed from the memory store.
+	cacheSweepInterval = time.Minute
+	// defaultCacheMaxEntries bounds the memory store, new entries are not cached once it is full.
+	defaultCacheMaxEntries = 10000
+)
+
+type memoryCacheStore struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryCacheEntry
+	tags       map[string]map[string]struct{}
+	maxEntries int
+	lastSweep  time.Time
+}
+
+type memoryCacheEntry struct {
+	entry    CacheEntry
+	tags     []string
+	expireAt time.Time
+}
+
+// NewMemoryCacheStore creates a response cache store local to the process, for single instance services.
+func NewMemoryCacheStore() *memoryCacheStore {
+	return &memoryCacheStore{
+		entries:    make(map[string]*memoryCacheEntry),
+		tags:       make(map[string]map[string]struct{}),
+		maxEntries: defaultCacheMaxEntries,
+		lastSweep:  time.Now(),
+	}
+}
+
+func (s *memoryCacheStore) Get(_ context.Context, key string) (*CacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expireAt) {
+		return nil, nil
+	}
+
+	entry := e.entry
+	return &entry, nil
+}
+
+func (s *memoryCacheStore) Set(_ context.Context, key string, entry CacheEntry, tags []string, ttl time.Duration) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		return nil
+	}
+
+	s.remove(key)
+	s.entries[key] = &memoryCacheEntry{entry: entry, tags: tags, expireAt: now.Add(ttl)}
+	for _, t := range tags {
+		if s.tags[t] == nil {
+			s.tags[t] = make(map[string]struct{})
+		}
+		s.tags[t][key] = struct{}{}
+	}
+
+	return nil
+}
+
+func (s *memoryCacheStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.remove(key)
+	}
+
+	return nil
+}
+
+func (s *memoryCacheStore) DeleteTags(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range tags {
+		for key := range s.tags[t] {
+			s.remove(key)
+		}
+		delete(s.tags, t)
+	}
+
+	return nil
+}
+
+// remove deletes an entry and its tag references.
+func (s *memoryCacheStore) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(s.entries, key)
+	for _, t := range e.tags {
+		delete(s.tags[t], key)
+		if len(s.tags[t]) == 0 {
+			delete(s.tags, t)
+		}
+	}
+}
+
+// sweep removes the expired entries.
+func (s *memoryCacheStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < cacheSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.After(e.expireAt) {
+			s.remove(key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisCacheStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisCacheStore creates a response cache store shared by every instance, the client
+// is usually the redisc component client. Tags are sets of entry keys, every command touches
+// a single key so it works on Redis Cluster.
+func NewRedisCacheStore(client redis.Cmdable) *redisCacheStore {
+	return &redisCacheStore{client: client}
+}
+
+func (s *redisCacheStore) Get(ctx context.Context, key string) (*CacheEntry, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (s *redisCacheStore) Set(ctx context.Context, key string, entry CacheEntry, tags []string, ttl time.Duration) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, value, ttl)
+		for _, t := range tags {
+			pipe.SAdd(ctx, t, key)
+			// a tag lives as long as its longest entry (Redis 7 expire options)
+			pipe.ExpireGT(ctx, t, ttl)
+			pipe.ExpireNX(ctx, t, ttl)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisCacheStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// one DEL per key, keys are in different cluster slots
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisCacheStore) DeleteTags(ctx context.Context, tags ...string) error {
+	for _, t := range tags {
+		keys, err := s.client.SMembers(ctx, t).Result()
+		if err != nil {
+			return err
+		}
+
+		if err := s.Delete(ctx, append(keys, t)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+
+	defaultCompressMinSize = 1024
+)
+
+// DefaultCompressContentTypes are the content types compressed by default.
+var DefaultCompressContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+	"text/",
+}
+
+// CompressConfig configures the response compression middleware.
+type CompressConfig struct {
+	// Encodings in order of preference. Default zstd, br, gzip.
+	Encodings []string
+	// MinSize is the smallest response compressed in bytes. Default 1024.
+	MinSize int
+	// ContentTypes are the content type prefixes compressed. Default DefaultCompressContentTypes.
+	ContentTypes []string
+}
+
+// Compress compresses responses with the default configuration.
+func Compress() gin.HandlerFunc {
+	return CompressWithConfig(CompressConfig{})
+}
+
+// CompressWithConfig compresses responses with the preferred encoding accepted by the client
+// (Accept-Encoding), when their content type is allowed and they are at least MinSize bytes.
+// Responses already encoded, HEAD requests and upgrades are left untouched.
+func CompressWithConfig(cfg CompressConfig) gin.HandlerFunc {
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = []string{EncodingZstd, EncodingBrotli, EncodingGzip}
+	}
+	for _, e := range cfg.Encodings {
+		if _, ok := encoderPools[e]; !ok {
+			panic("compress: unsupported encoding " + e)
+		}
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = defaultCompressMinSize
+	}
+	if cfg.ContentTypes == nil {
+		cfg.ContentTypes = DefaultCompressContentTypes
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), cfg.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        cfg.MinSize,
+			contentTypes:   cfg.ContentTypes,
+		}
+		c.Writer = w
+
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding returns the first of the server encodings accepted by the client with q > 0.
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		if name == "*" {
+			wildcard = q > 0
+			continue
+		}
+		accepted[name] = q > 0
+	}
+
+	for _, e := range encodings {
+		if ok, listed := accepted[e]; ok || (!listed && wildcard) {
+			return e
+		}
+	}
+	return ""
+}
+
+// compressWriter buffers the response until minSize bytes to decide whether it is compressed.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding     string
+	minSize      int
+	contentTypes []string
+
+	buf     []byte
+	decided bool
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is deferred until the encoding is decided.
+func (w *compressWriter) WriteHeaderNow() {
+	if w.decided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *compressWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// decide starts compressing when the response is eligible, then writes the buffered bytes.
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+	if w.compressible(header) {
+		header.Add("Vary", "Accept-Encoding")
+
+		if len(w.buf) >= w.minSize {
+			header.Set("Content-Encoding", w.encoding)
+			header.Del("Content-Length")
+			// the representation changes, a strong ETag must not be shared with the identity response
+			if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+			w.encoder = getEncoder(w.encoding, w.ResponseWriter)
+		}
+	}
+
+	buf := w.buf
+	w.buf = nil
+
+	if len(buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	switch w.Status() {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf)
+	}
+	contentType = strings.ToLower(contentType)
+
+	return slices.ContainsFunc(w.contentTypes, func(prefix string) bool {
+		return strings.HasPrefix(contentType, prefix)
+	})
+}
+
+// close writes the responses smaller than minSize and ends the compressed stream.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if len(w.buf) == 0 {
+			w.decided = true
+			return
+		}
+		_ = w.decide()
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		putEncoder(w.encoding, w.encoder)
+		w.encoder = nil
+	}
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if f, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+var encoderPools = map[string]*sync.Pool{
+	EncodingGzip: {New: func() any {
+		zw, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return zw
+	}},
+	EncodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	EncodingZstd: {New: func() any {
+		zw, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return zw
+	}},
+}
+
+func getEncoder(encoding string, dst io.Writer) io.WriteCloser {
+	switch e := encoderPools[encoding].Get().(type) {
+	case *gzip.Writer:
+		e.Reset(dst)
+		return e
+	case *brotli.Writer:
+		e.Reset(dst)
+		return e
+	case *zstd.Encoder:
+		e.Reset(dst)
+		return e
+	}
+	return nil
+}
+
+func putEncoder(encoding string, e io.WriteCloser) {
+	encoderPools[encoding].Put(e)
+}
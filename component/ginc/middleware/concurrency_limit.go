@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 5
+	defaultMaxLimit     = 1000
+
+	defaultAIMDBackoff          = 0.9
+	defaultAIMDLatencyThreshold = time.Second
+
+	defaultGradientTolerance = 1.5
+	defaultGradientSmoothing = 0.2
+	defaultGradientWindow    = 600
+)
+
+// LimitAlgorithm computes the concurrency limit from the completed requests, it must be safe for concurrent use.
+type LimitAlgorithm interface {
+	// Limit returns the current maximum number of requests in flight.
+	Limit() int
+	// OnSample records a completed request, inFlight is the number of requests in flight when it started
+	// and overloaded reports a response showing overload, ex: 503 or 504.
+	OnSample(latency time.Duration, inFlight int, overloaded bool)
+}
+
+// fixedLimit never changes.
+type fixedLimit int
+
+// NewFixedLimit returns a constant limit.
+func NewFixedLimit(limit int) LimitAlgorithm {
+	if limit < 1 {
+		panic("concurrency limit: fixed limit must be positive")
+	}
+	return fixedLimit(limit)
+}
+
+func (l fixedLimit) Limit() int                        { return int(l) }
+func (l fixedLimit) OnSample(time.Duration, int, bool) {}
+
+// LimitBounds bounds an adaptive limit.
+type LimitBounds struct {
+	// Initial is the limit before any sample. Default 20.
+	Initial int
+	// Min is the lowest limit. Default 5, or Max when it is lower.
+	Min int
+	// Max is the highest limit. Default 1000.
+	Max int
+}
+
+func (b *LimitBounds) setDefaults() {
+	if b.Initial <= 0 {
+		b.Initial = defaultInitialLimit
+	}
+	if b.Max <= 0 {
+		b.Max = defaultMaxLimit
+	}
+	if b.Min <= 0 {
+		b.Min = min(defaultMinLimit, b.Max)
+	}
+	if b.Min > b.Max {
+		panic("concurrency limit: min limit is greater than max limit")
+	}
+	b.Initial = min(max(b.Initial, b.Min), b.Max)
+}
+
+func (b LimitBounds) clamp(limit float64) float64 {
+	return math.Min(math.Max(limit, float64(b.Min)), float64(b.Max))
+}
+
+// AIMDLimitConfig configures the additive increase multiplicative decrease limit.
+type AIMDLimitConfig struct {
+	LimitBounds
+	// Backoff multiplies the limit on overload. Default 0.9.
+	Backoff float64
+	// LatencyThreshold is the latency over which a request counts as overloaded. Default 1s.
+	LatencyThreshold time.Duration
+}
+
+type aimdLimit struct {
+	cfg   AIMDLimitConfig
+	mu    sync.Mutex
+	limit float64
+}
+
+// NewAIMDLimit returns a limit increased by one while requests are fast and the server is busy,
+// and multiplied by the backoff when a request is slower than the latency threshold or overloaded.
+// It suits services with a known latency objective.
+func NewAIMDLimit(cfg AIMDLimitConfig) LimitAlgorithm {
+	cfg.setDefaults()
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = defaultAIMDBackoff
+	}
+	if cfg.LatencyThreshold <= 0 {
+		cfg.LatencyThreshold = defaultAIMDLatencyThreshold
+	}
+
+	return &aimdLimit{cfg: cfg, limit: float64(cfg.Initial)}
+}
+
+func (l *aimdLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *aimdLimit) OnSample(latency time.Duration, inFlight int, overloaded bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case overloaded || latency > l.cfg.LatencyThreshold:
+		l.limit = l.cfg.clamp(l.limit * l.cfg.Backoff)
+	// grows only when the limit is used, an idle server says nothing about its capacity
+	case float64(inFlight)*2 >= l.limit:
+		l.limit = l.cfg.clamp(l.limit + 1)
+	}
+}
+
+// GradientLimitConfig configures the gradient limit.
+type GradientLimitConfig struct {
+	LimitBounds
+	// Tolerance is the latency increase tolerated before the limit decreases, 1.5 tolerates +50%. Default 1.5.
+	Tolerance float64
+	// Smoothing weights the new limit against the current one, between 0 and 1. Default 0.2.
+	Smoothing float64
+	// Window is the number of samples of the long term latency average. Default 600.
+	Window int
+}
+
+type gradientLimit struct {
+	cfg     GradientLimitConfig
+	mu      sync.Mutex
+	limit   float64
+	longRTT float64
+}
+
+// NewGradientLimit returns a limit following the gradient between the long term average latency and the
+// latency of each request: when requests get slower than the average, requests are queuing and the limit
+// decreases, otherwise it grows by its square root, the queue allowed to find more capacity.
+// It needs no latency objective.
+func NewGradientLimit(cfg GradientLimitConfig) LimitAlgorithm {
+	cfg.setDefaults()
+	if cfg.Tolerance < 1 {
+		cfg.Tolerance = defaultGradientTolerance
+	}
+	if cfg.Smoothing <= 0 || cfg.Smoothing > 1 {
+		cfg.Smoothing = defaultGradientSmoothing
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultGradientWindow
+	}
+
+	return &gradientLimit{cfg: cfg, limit: float64(cfg.Initial)}
+}
+
+func (l *gradientLimit) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *gradientLimit) OnSample(latency time.Duration, inFlight int, _ bool) {
+	rtt := float64(latency)
+	if rtt <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// exponential moving average over the window
+	if l.longRTT == 0 {
+		l.longRTT = rtt
+	} else {
+		factor := 2 / float64(l.cfg.Window+1)
+		l.longRTT = l.longRTT*(1-factor) + rtt*factor
+	}
+
+	// recovers faster when latency drops for good, ex: after a slow dependency recovered
+	if l.longRTT/rtt > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// an idle server says nothing about its capacity
+	if float64(inFlight)*2 < l.limit {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.cfg.Tolerance*l.longRTT/rtt))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = l.cfg.clamp(l.limit*(1-l.cfg.Smoothing) + newLimit*l.cfg.Smoothing)
+}
@@ -1,21 +1,231 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins is a list of origins allowed to make cross-origin requests.
+	// An origin can be exact (https://example.com), a wildcard subdomain (https://*.example.com) or "*" for any origin.
+	AllowOrigins []string
+	// AllowOriginRegexps is a list of regular expressions matched against the whole origin.
+	AllowOriginRegexps []string
+	// AllowCredentials allows cookies and authorization headers, it cannot be used with the "*" origin.
+	AllowCredentials bool
+	// AllowHeaders is a list of request headers the client is allowed to use.
+	AllowHeaders []string
+	// ExposeHeaders is a list of response headers the client is allowed to read.
+	ExposeHeaders []string
+	// AllowMethods is a list of methods the client is allowed to use.
+	AllowMethods []string
+	// MaxAge is how long the result of a preflight request can be cached, 0 to disable caching.
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig allows any origin without credentials.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+			http.MethodDelete, http.MethodHead, http.MethodOptions,
+		},
+		MaxAge: time.Hour,
+	}
+}
+
+// Validate checks the config, browsers reject credentials with a wildcard origin.
+func (cfg CORSConfig) Validate() error {
+	if len(cfg.AllowOrigins) == 0 && len(cfg.AllowOriginRegexps) == 0 {
+		return errors.New("cors: at least one allowed origin is required")
+	}
+
+	for _, o := range cfg.AllowOrigins {
+		if o == "*" && cfg.AllowCredentials {
+			return errors.New("cors: credentials cannot be allowed with the \"*\" origin")
+		}
+		if strings.Count(o, "*") > 1 {
+			return errors.New("cors: origin " + o + " has more than one wildcard")
+		}
+	}
+
+	for _, r := range cfg.AllowOriginRegexps {
+		if _, err := regexp.Compile(r); err != nil {
+			return errors.New("cors: invalid origin regexp " + r + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// AllowCORS allows any origin without credentials.
+//
+// Deprecated: use CORSWithConfig to restrict origins, headers and methods.
 func AllowCORS() gin.HandlerFunc {
+	return CORSWithConfig(DefaultCORSConfig())
+}
+
+// CORSWithConfig returns a CORS middleware, it panics if the config is not valid.
+func CORSWithConfig(cfg CORSConfig) gin.HandlerFunc {
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	p := newCORSPolicy(cfg)
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		origin := c.Request.Header.Get("Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+		header := c.Writer.Header()
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// responses differ by origin unless every origin gets "*"
+		if !p.allowAll || p.allowCredentials {
+			header.Add("Vary", "Origin")
+		}
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		// not a cross-origin request
+		if origin == "" {
+			c.Next()
 			return
 		}
 
+		if !p.isOriginAllowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			// the browser blocks the response without CORS headers
+			c.Next()
+			return
+		}
+
+		if p.allowAll && !p.allowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if p.allowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Set("Access-Control-Allow-Methods", p.allowMethods)
+			if p.allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", p.allowHeaders)
+			}
+			if p.maxAge != "" {
+				header.Set("Access-Control-Max-Age", p.maxAge)
+			}
+
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if p.exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+		}
+
 		c.Next()
 	}
 }
+
+// corsPolicy is the pre-computed form of CORSConfig.
+type corsPolicy struct {
+	allowAll         bool
+	exactOrigins     map[string]struct{}
+	wildcardOrigins  [][2]string // prefix and suffix around the "*"
+	regexpOrigins    []*regexp.Regexp
+	allowCredentials bool
+	allowHeaders     string
+	exposeHeaders    string
+	allowMethods     string
+	maxAge           string
+}
+
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		exactOrigins:     make(map[string]struct{}),
+		allowCredentials: cfg.AllowCredentials,
+		allowHeaders:     joinHeaders(cfg.AllowHeaders, http.CanonicalHeaderKey),
+		exposeHeaders:    joinHeaders(cfg.ExposeHeaders, http.CanonicalHeaderKey),
+		allowMethods:     joinHeaders(cfg.AllowMethods, strings.ToUpper),
+	}
+
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	for _, o := range cfg.AllowOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		switch {
+		case o == "*":
+			p.allowAll = true
+		case strings.Contains(o, "*"):
+			prefix, suffix, _ := strings.Cut(o, "*")
+			p.wildcardOrigins = append(p.wildcardOrigins, [2]string{prefix, suffix})
+		case o != "":
+			p.exactOrigins[o] = struct{}{}
+		}
+	}
+
+	for _, r := range cfg.AllowOriginRegexps {
+		p.regexpOrigins = append(p.regexpOrigins, regexp.MustCompile("^(?:"+r+")$"))
+	}
+
+	return p
+}
+
+func (p *corsPolicy) isOriginAllowed(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if _, ok := p.exactOrigins[origin]; ok {
+		return true
+	}
+
+	for _, w := range p.wildcardOrigins {
+		prefix, suffix := w[0], w[1]
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			// the wildcard matches subdomains only, not paths or ports
+			if !strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+				return true
+			}
+		}
+	}
+
+	for _, r := range p.regexpOrigins {
+		if r.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinHeaders(values []string, normalize func(string) string) string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, normalize(v))
+		}
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gocql/gocql"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"gorm.io/gorm"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// errorHandlerKey marks the requests whose errors are rendered by ErrorHandler, see HasErrorHandler.
+const errorHandlerKey = "error_handler"
+
+// HandlerFunc is a gin handler returning its error, see Handle.
+type HandlerFunc func(c *gin.Context) error
+
+// ErrorMapper converts an error into an HTTP error, it returns nil when it does not know the error.
+type ErrorMapper func(err error) *core.DefaultError
+
+// ErrorHandlerConfig configures the error handler middleware.
+type ErrorHandlerConfig struct {
+	// Mappers are tried in order before the error carriers of core.ToDefaultError,
+	// the first non nil result is rendered. DriverErrorMapper is always tried last.
+	Mappers []ErrorMapper
+}
+
+// Handle adapts a handler returning an error, the error is rendered by ErrorHandler.
+//
+//	router.GET("/tasks/:id", middleware.Handle(func(c *gin.Context) error {
+//		task, err := biz.GetTask(c.Request.Context(), c.Param("id"))
+//		if err != nil {
+//			return err
+//		}
+//		c.JSON(http.StatusOK, core.ResponseData(task))
+//		return nil
+//	}))
+func Handle(h HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h(c); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+// ErrorHandler renders the last error of c.Errors with the driver error mappings.
+func ErrorHandler() gin.HandlerFunc {
+	return ErrorHandlerWithConfig(ErrorHandlerConfig{})
+}
+
+// ErrorHandlerWithConfig renders the last error added with c.Error (or returned to Handle)
+// as a core.StandardResponse once the handlers are done, unless a response was already written.
+// The status code comes from the error (core.StatusCodeCarrier), the mappers, or c.Status.
+// Messages of 5xx errors without a status code are replaced by a generic one, the original
+// error is only sent in the description when debug mode is on.
+func ErrorHandlerWithConfig(cfg ErrorHandlerConfig) gin.HandlerFunc {
+	mappers := append(append([]ErrorMapper{}, cfg.Mappers...), DriverErrorMapper)
+
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		c.Set(errorHandlerKey, true)
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		ctx := c.Request.Context()
+
+		if c.Writer.Written() {
+			// c.AbortWithError or a failure while streaming the response
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				slog.ErrorContext(ctx, "request error", "status", c.Writer.Status(), "error", err)
+			}
+			return
+		}
+
+		de := toHTTPError(err, mappers, c.Writer.Status(), core.GetRequestID(ctx))
+		if de.StatusCode() >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "request error", "status", de.StatusCode(), "error", err)
+		}
+
+		writeError(c, ec, de, err)
+	}
+}
+
+// HasErrorHandler reports whether ErrorHandler renders the errors of the request.
+func HasErrorHandler(c *gin.Context) bool {
+	return c.GetBool(errorHandlerKey)
+}
+
+// WriteError renders err like ErrorHandler with the driver error mappings, for handlers that may
+// run without it, see HasErrorHandler.
+func WriteError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+
+	de := toHTTPError(err, []ErrorMapper{DriverErrorMapper}, c.Writer.Status(), core.GetRequestID(ctx))
+	if de.StatusCode() >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "request error", "status", de.StatusCode(), "error", err)
+	}
+
+	writeError(c, mwutil.ErrorContext(), de, err)
+}
+
+// DriverErrorMapper maps the not found errors of gorm, go-redis, mongo and gocql to 404,
+// context deadlines to 504 and bodies over the BodyLimit to 413.
+func DriverErrorMapper(err error) *core.DefaultError {
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, core.ErrRecordNotFound),
+		errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, redis.Nil),
+		errors.Is(err, mongo.ErrNoDocuments),
+		errors.Is(err, gocql.ErrNotFound):
+		return core.ErrNotFound.WithWrap(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return core.ErrGatewayTimeout.WithWrap(err)
+	case errors.As(err, &maxBytesErr):
+		return core.ErrRequestEntityTooLarge.WithWrap(err)
+	}
+	return nil
+}
+
+// toHTTPError converts err to a DefaultError, status is the response status set so far.
+func toHTTPError(err error, mappers []ErrorMapper, status int, requestID string) *core.DefaultError {
+	if c := core.StatusCodeCarrier(nil); !errors.As(err, &c) || c.StatusCode() == 0 {
+		var mapped *core.DefaultError
+		for _, m := range mappers {
+			if mapped = m(err); mapped != nil {
+				break
+			}
+		}
+
+		switch {
+		case mapped != nil:
+			err = mapped
+		case status >= http.StatusBadRequest && status < http.StatusInternalServerError:
+			// c.Status(4xx) then c.Error(err), the message is meant for the client
+			err = core.DefaultError{
+				StatusField: http.StatusText(status),
+				ErrorField:  err.Error(),
+				CodeField:   status,
+			}.WithWrap(err)
+		default:
+			err = core.ErrInternalServerError.WithWrap(err)
+		}
+	}
+
+	return core.ToDefaultError(err, requestID)
+}
+
+// writeError renders de with the standard error envelope, cause is shown in debug mode.
+func writeError(c *gin.Context, ec *core.ErrorContext, de *core.DefaultError, cause error) {
+	if ve := (*core.ValidationError)(nil); errors.As(cause, &ve) {
+		resp := ec.ValidationError(ve.Message, ve.Fields)
+		resp.Error.RequestID = de.RequestID()
+
+		core.WriteStandardErrorResponse(c, http.StatusBadRequest, resp)
+		c.Abort()
+		return
+	}
+
+	code := de.ID()
+	if code == "" {
+		code = mwutil.ErrorCode(de.StatusCode())
+	}
+
+	description := de.Debug()
+	if description == "" && cause != nil && cause.Error() != de.Error() {
+		description = cause.Error()
+	}
+
+	resp := ec.CustomError(code, de.Reason(), de.Error(), description)
+	resp.Error.RequestID = de.RequestID()
+
+	core.WriteStandardErrorResponse(c, de.StatusCode(), resp)
+	c.Abort()
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WrapHTTP runs a net/http middleware, func(http.Handler) http.Handler, as a gin middleware,
+// so middlewares written for httpserverc are shared by both servers. The next gin handlers run
+// with the request and the response writer given by the middleware, ex: with a request context value
+// or a status capturing writer. The gin chain is aborted when the middleware does not call next.
+func WrapHTTP(mw func(http.Handler) http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		called := false
+		origin := c.Writer
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			if w != http.ResponseWriter(origin) {
+				c.Writer = &httpWriter{ResponseWriter: origin, w: w}
+			}
+
+			c.Next()
+			c.Writer = origin
+		})
+
+		mw(next).ServeHTTP(origin, c.Request)
+
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// httpWriter sends the response through the writer of a net/http middleware, the gin writer
+// it wraps in the end keeps the status and size.
+type httpWriter struct {
+	gin.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (w *httpWriter) Header() http.Header {
+	return w.w.Header()
+}
+
+func (w *httpWriter) WriteHeader(status int) {
+	w.w.WriteHeader(status)
+}
+
+func (w *httpWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+func (w *httpWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the pending status through the middleware writer, gin calls it for empty responses.
+func (w *httpWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.w.WriteHeader(w.Status())
+	}
+}
+
+func (w *httpWriter) Flush() {
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+		return
+	}
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on responses replayed from the store.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL         = 24 * time.Hour
+	defaultIdempotencyLockTimeout = time.Minute
+	defaultIdempotencyMaxBodySize = 1 << 20 // 1MB
+	defaultIdempotencyKeyPrefix   = "idempotency"
+	maxIdempotencyKeyLength       = 255
+)
+
+// replaySkipHeaders are not replayed from a stored response, they belong to the original request.
+var replaySkipHeaders = []string{
+	"Date",
+	"Set-Cookie",
+	core.HeaderRequestID,
+	"Ratelimit-Limit",
+	"Ratelimit-Remaining",
+	"Ratelimit-Reset",
+}
+
+// IdempotencyRecord is the state of an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request payload.
+	Fingerprint string `json:"fingerprint"`
+	// Completed is false while the first request is in flight.
+	Completed bool        `json:"completed"`
+	Status    int         `json:"status,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      []byte      `json:"body,omitempty"`
+}
+
+// IdempotencyStore keeps the idempotency records, see NewMemoryIdempotencyStore and NewRedisIdempotencyStore.
+type IdempotencyStore interface {
+	// Lock reserves key for a request in flight until ttl. When the key is already used,
+	// it returns the existing record and false.
+	Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+	// Save stores the completed record of a locked key for ttl.
+	Save(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Unlock releases a locked key without a response, so the request can be retried.
+	Unlock(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the idempotency middleware.
+type IdempotencyConfig struct {
+	// Store keeps the records. Default in-memory, use the Redis store when running several instances.
+	Store IdempotencyStore
+	// TTL is how long completed responses are replayed. Default 24h.
+	TTL time.Duration
+	// LockTimeout releases the key of a request in flight that never completed (crash). Default 1m.
+	LockTimeout time.Duration
+	// Methods are the methods honoring the header. Default POST and PATCH.
+	Methods []string
+	// Required rejects requests without the header with 400.
+	Required bool
+	// MaxBodySize is the largest request body fingerprinted, larger requests are rejected with 413. Default 1MB.
+	// Larger responses are not stored and can be retried.
+	MaxBodySize int
+	// KeyPrefix namespaces the keys in the store. Default "idempotency".
+	KeyPrefix string
+}
+
+// Idempotency honors the Idempotency-Key header with an in-memory store.
+func Idempotency() gin.HandlerFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{})
+}
+
+// IdempotencyWithConfig makes retried requests with the same Idempotency-Key header safe:
+// the first request runs and its response is stored, repeats get the stored response
+// with the Idempotent-Replayed header. A repeat while the first request is in flight,
+// or with a different payload, is rejected with 409. Keys are scoped by route and requester.
+// 5xx responses and panics are not stored so the request can be retried.
+func IdempotencyWithConfig(cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaultIdempotencyLockTimeout
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultIdempotencyMaxBodySize
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = defaultIdempotencyKeyPrefix
+	}
+
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		if !slices.Contains(cfg.Methods, c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		idemKey := c.GetHeader(HeaderIdempotencyKey)
+		if idemKey == "" {
+			if cfg.Required {
+				writeIdempotencyError(c, ec, http.StatusBadRequest, "IDEMPOTENCY_KEY_REQUIRED", "The Idempotency-Key header is required")
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(idemKey) > maxIdempotencyKeyLength {
+			writeIdempotencyError(c, ec, http.StatusBadRequest, "IDEMPOTENCY_KEY_INVALID", "The Idempotency-Key header is too long")
+			return
+		}
+
+		fingerprint, ok := fingerprintRequest(c.Request, cfg.MaxBodySize)
+		if !ok {
+			writeIdempotencyError(c, ec, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "The request body is too large for an idempotent request")
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := idempotencyKey(c, cfg.KeyPrefix, idemKey)
+
+		record, locked, err := cfg.Store.Lock(ctx, key, fingerprint, cfg.LockTimeout)
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency store error", "error", err)
+			c.Next()
+			return
+		}
+
+		if !locked {
+			switch {
+			case record.Fingerprint != fingerprint:
+				writeIdempotencyError(c, ec, http.StatusConflict, "IDEMPOTENCY_KEY_REUSED", "The Idempotency-Key was already used with a different request")
+			case !record.Completed:
+				writeIdempotencyError(c, ec, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE", "A request with the same Idempotency-Key is in progress")
+			default:
+				replayResponse(c, record)
+			}
+			return
+		}
+
+		w := &captureWriter{ResponseWriter: c.Writer, limit: cfg.MaxBodySize}
+		c.Writer = w
+
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+
+			// released on panic, 5xx, an oversized response or an error left to ErrorHandler
+			if completed {
+				return
+			}
+			if err := cfg.Store.Unlock(context.WithoutCancel(ctx), key); err != nil {
+				slog.ErrorContext(ctx, "idempotency store error", "error", err)
+			}
+		}()
+
+		c.Next()
+
+		// nothing written: the error is rendered later by ErrorHandler and cannot be captured
+		if !w.Written() || w.Status() >= http.StatusInternalServerError || w.overflow {
+			return
+		}
+
+		header := w.Header().Clone()
+		for _, h := range replaySkipHeaders {
+			header.Del(h)
+		}
+
+		err = cfg.Store.Save(context.WithoutCancel(ctx), key, IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      w.Status(),
+			Header:      header,
+			Body:        w.body.Bytes(),
+		}, cfg.TTL)
+		if err != nil {
+			slog.ErrorContext(ctx, "idempotency store error", "error", err)
+			return
+		}
+
+		completed = true
+	}
+}
+
+// fingerprintRequest hashes the method, URI and body, it returns false when the body is too large.
+func fingerprintRequest(r *http.Request, limit int) (string, bool) {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+		if err != nil || len(body) > limit {
+			return "", false
+		}
+		r.Body = readCloser{Reader: bytes.NewReader(body), Closer: r.Body}
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// idempotencyKey scopes the client key by requester and route, so clients cannot collide.
+func idempotencyKey(c *gin.Context, prefix, idemKey string) string {
+	scope := "anonymous"
+	if r := core.GetRequester(c.Request.Context()); r != nil && r.GetSubject() != "" {
+		scope = r.GetSubject()
+	}
+
+	sum := sha256.Sum256([]byte(scope + "\n" + c.Request.Method + " " + c.FullPath() + "\n" + idemKey))
+	return prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+func replayResponse(c *gin.Context, record *IdempotencyRecord) {
+	header := c.Writer.Header()
+	for k, v := range record.Header {
+		header[k] = v
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	c.Status(record.Status)
+	if len(record.Body) > 0 {
+		_, _ = c.Writer.Write(record.Body)
+	} else {
+		c.Writer.WriteHeaderNow()
+	}
+	c.Abort()
+}
+
+func writeIdempotencyError(c *gin.Context, ec *core.ErrorContext, status int, code, message string) {
+	core.WriteStandardErrorResponse(c, status, ec.CustomError(code, "", message, ""))
+	c.Abort()
+}
+
+// captureWriter keeps the response body up to limit, overflow is set when it is larger.
+type captureWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(b) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
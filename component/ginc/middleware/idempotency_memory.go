@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// idempotencySweepInterval is how often expired records are removed from the memory store.
+const idempotencySweepInterval = time.Minute
+
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryIdempotencyRecord
+	lastSweep time.Time
+}
+
+type memoryIdempotencyRecord struct {
+	record   IdempotencyRecord
+	expireAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an idempotency store local to the process, for single instance services.
+func NewMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		records:   make(map[string]*memoryIdempotencyRecord),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryIdempotencyStore) Lock(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if r, ok := s.records[key]; ok && now.Before(r.expireAt) {
+		record := r.record
+		return &record, false, nil
+	}
+
+	s.records[key] = &memoryIdempotencyRecord{
+		record:   IdempotencyRecord{Fingerprint: fingerprint},
+		expireAt: now.Add(ttl),
+	}
+
+	return nil, true, nil
+}
+
+func (s *memoryIdempotencyStore) Save(_ context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = &memoryIdempotencyRecord{record: record, expireAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Unlock(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok && !r.record.Completed {
+		delete(s.records, key)
+	}
+
+	return nil
+}
+
+// sweep removes the expired records.
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idempotencySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, r := range s.records {
+		if now.After(r.expireAt) {
+			delete(s.records, key)
+		}
+	}
+}
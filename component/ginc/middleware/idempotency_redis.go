@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyLockScript returns the record of a used key, or reserves it and returns nil.
+// KEYS[1] record key; ARGV: in flight record, ttl (ms)
+var idempotencyLockScript = redis.NewScript(`
+local record = redis.call('GET', KEYS[1])
+if record then
+	return record
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`)
+
+// idempotencyUnlockScript deletes a record only while its request is in flight.
+// KEYS[1] record key
+var idempotencyUnlockScript = redis.NewScript(`
+local record = redis.call('GET', KEYS[1])
+if record and not cjson.decode(record).completed then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+type redisIdempotencyStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisIdempotencyStore creates an idempotency store shared by every instance,
+// the client is usually the redisc component client.
+func NewRedisIdempotencyStore(client redis.Cmdable) *redisIdempotencyStore {
+	return &redisIdempotencyStore{client: client}
+}
+
+func (s *redisIdempotencyStore) Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	inFlight, err := json.Marshal(IdempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err := idempotencyLockScript.Run(ctx, s.client, []string{key}, inFlight, ttl.Milliseconds()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, false, err
+	}
+
+	return &record, false, nil
+}
+
+func (s *redisIdempotencyStore) Save(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (s *redisIdempotencyStore) Unlock(ctx context.Context, key string) error {
+	return idempotencyUnlockScript.Run(ctx, s.client, []string{key}).Err()
+}
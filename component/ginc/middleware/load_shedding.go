@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+const defaultShedRetryAfter = time.Second
+
+// Priority is the class of a request, lower classes are shed first.
+type Priority int
+
+const (
+	// PriorityLow requests are shed once 75% of the limit is in flight, ex: reports, exports.
+	PriorityLow Priority = iota
+	// PriorityNormal requests are shed once the limit is in flight.
+	PriorityNormal
+	// PriorityHigh requests may exceed the limit by 25%, ex: checkout.
+	PriorityHigh
+	// PriorityCritical requests are never shed nor counted, ex: health checks and streaming routes.
+	PriorityCritical
+)
+
+// priorityShare is the share of the limit each priority may use.
+var priorityShare = map[Priority]float64{
+	PriorityLow:    0.75,
+	PriorityNormal: 1,
+	PriorityHigh:   1.25,
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return strconv.Itoa(int(p))
+}
+
+// ParsePriority parses low, normal, high or critical.
+func ParsePriority(s string) (Priority, error) {
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityCritical} {
+		if strings.EqualFold(s, p.String()) {
+			return p, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %q, expected low, normal, high or critical", s)
+}
+
+// LoadSheddingConfig configures the load shedding middleware.
+type LoadSheddingConfig struct {
+	// Limit computes the maximum number of requests in flight. Default NewGradientLimit with default bounds.
+	Limit LimitAlgorithm
+	// Routes sets the priority of the route templates starting with a prefix, keyed by "METHOD /prefix"
+	// or "/prefix", ex: "/v1/reports": PriorityLow. The longest prefix wins. Default PriorityNormal.
+	Routes map[string]Priority
+	// Priority, when set, overrides the route priority, ex: by API key plan.
+	Priority func(c *gin.Context) Priority
+	// RetryAfter is advised to shed clients. Default 1s.
+	RetryAfter time.Duration
+	// MeterProvider provides the limiter metrics. Default the global meter provider.
+	MeterProvider metric.MeterProvider
+}
+
+// LoadShedding limits the requests in flight with an adaptive gradient limit, see LoadSheddingWithConfig.
+func LoadShedding() gin.HandlerFunc {
+	return LoadSheddingWithConfig(LoadSheddingConfig{})
+}
+
+// LoadSheddingWithConfig answers 503 with Retry-After right away when the requests in flight reach the share
+// of the limit of their priority, instead of queuing them until they time out. Each admitted request is
+// a latency sample adjusting the limit, 503 and 504 responses count as overload.
+// It records http.server.concurrency.limit, http.server.concurrency.in_flight and
+// http.server.shed_requests by priority. Place it after the metrics and tracing middlewares and before
+// the timeout middleware, so timeouts are measured.
+func LoadSheddingWithConfig(cfg LoadSheddingConfig) gin.HandlerFunc {
+	if cfg.Limit == nil {
+		cfg.Limit = NewGradientLimit(GradientLimitConfig{})
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = defaultShedRetryAfter
+	}
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	ec := mwutil.ErrorContext()
+	retryAfter := strconv.Itoa(max(1, ceilSeconds(cfg.RetryAfter)))
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+	)
+
+	meter := cfg.MeterProvider.Meter(instrumentationName)
+
+	shed, err := meter.Int64Counter(
+		"http.server.shed_requests",
+		metric.WithDescription("Number of HTTP requests rejected by load shedding."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	limitGauge, err := meter.Int64ObservableGauge(
+		"http.server.concurrency.limit",
+		metric.WithDescription("Current concurrency limit of load shedding."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	inFlightGauge, err := meter.Int64ObservableGauge(
+		"http.server.concurrency.in_flight",
+		metric.WithDescription("Number of HTTP requests counted by load shedding."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		mu.Lock()
+		n := inFlight
+		mu.Unlock()
+
+		o.ObserveInt64(limitGauge, int64(cfg.Limit.Limit()))
+		o.ObserveInt64(inFlightGauge, int64(n))
+		return nil
+	}, limitGauge, inFlightGauge)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(c *gin.Context) {
+		priority := PriorityNormal
+		if p, ok := routePrefixValue(c, cfg.Routes); ok {
+			priority = p
+		}
+		if cfg.Priority != nil {
+			priority = cfg.Priority(c)
+		}
+		if priority < PriorityLow || priority > PriorityCritical {
+			// unknown priorities are shed like normal requests
+			priority = PriorityNormal
+		}
+
+		if priority == PriorityCritical {
+			c.Next()
+			return
+		}
+
+		mu.Lock()
+		admitted := float64(inFlight) < float64(cfg.Limit.Limit())*priorityShare[priority]
+		if admitted {
+			inFlight++
+		}
+		started := inFlight
+		mu.Unlock()
+
+		if !admitted {
+			shed.Add(c.Request.Context(), 1, metric.WithAttributes(attribute.String("priority", priority.String())))
+
+			c.Header("Retry-After", retryAfter)
+			core.WriteStandardErrorResponse(c, http.StatusServiceUnavailable, ec.CustomError(
+				mwutil.ErrorCode(http.StatusServiceUnavailable),
+				"",
+				"Server is overloaded, please try again later",
+				"concurrency limit reached for priority "+priority.String(),
+			))
+			c.Abort()
+			return
+		}
+
+		start := time.Now()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+
+			status := c.Writer.Status()
+			overloaded := status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+			cfg.Limit.OnSample(time.Since(start), started, overloaded)
+		}()
+
+		c.Next()
+	}
+}
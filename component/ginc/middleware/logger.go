@@ -1,56 +1,288 @@
 package middleware
 
 import (
-	"fmt"
+	"bytes"
+	"io"
 	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/core"
+)
+
+const (
+	defaultLogMaxBodySize = 4 << 10 // 4KB
+	redactedValue         = "[REDACTED]"
 )
 
-type PrintInfo struct {
-	ClientIP  string        `json:"client_ip"`
-	Status    int           `json:"status"`
-	Method    string        `json:"method"`
-	Path      string        `json:"path"`
-	Latency   time.Duration `json:"latency"`
-	UserAgent string        `json:"user_agent"`
-	Header    string        `json:"header"`
+// DefaultRedactHeaders are the headers whose values are never logged.
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// DefaultLogBodyContentTypes are the content types whose bodies are logged when body capture is on.
+var DefaultLogBodyContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"text/",
+}
+
+// LoggerConfig configures the request logger.
+type LoggerConfig struct {
+	// Logger writes the records. Default slog.Default().
+	Logger *slog.Logger
+	// SkipPaths are not logged, matched against the request path and the route template, ex: /livez.
+	SkipPaths []string
+	// Skipper, when set, skips the requests it returns true for.
+	Skipper func(c *gin.Context) bool
+
+	// AllowHeaders, when set, are the only request headers logged. Default all of them.
+	AllowHeaders []string
+	// RedactHeaders are logged with a redacted value. Default DefaultRedactHeaders.
+	RedactHeaders []string
+	// SkipHeaders turns off header logging.
+	SkipHeaders bool
+
+	// RequestBody logs the first MaxBodySize bytes of the request body.
+	RequestBody bool
+	// ResponseBody logs the first MaxBodySize bytes of the response body.
+	ResponseBody bool
+	// MaxBodySize is the number of body bytes logged. Default 4KB.
+	MaxBodySize int
+	// BodyContentTypes are the content type prefixes whose bodies are logged. Default DefaultLogBodyContentTypes.
+	BodyContentTypes []string
+
+	// Sampling logs only a fraction (0 to 1) of the successful requests of a route, keyed by
+	// "METHOD /path/:param" or "/path/:param". Requests ending with a status >= 400 are always logged.
+	Sampling map[string]float64
+
+	// Level returns the level of a request record. Default error for 5xx, warn for 4xx, info otherwise.
+	Level func(c *gin.Context) slog.Level
 }
 
-// Logger is a custom logger middleware for the Gin framework
+// Logger logs every request with the default configuration.
 func Logger() gin.HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig logs one record per request once it is handled, with the status, numeric
+// latency (latency_ms), route, headers and optionally bodies, plus the trace, span and request IDs.
+// Use it before the otelgin and request ID middlewares so their IDs are set when the record is written.
+func LoggerWithConfig(cfg LoggerConfig) gin.HandlerFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultLogMaxBodySize
+	}
+	if cfg.RedactHeaders == nil {
+		cfg.RedactHeaders = DefaultRedactHeaders
+	}
+	if cfg.BodyContentTypes == nil {
+		cfg.BodyContentTypes = DefaultLogBodyContentTypes
+	}
+	if cfg.Level == nil {
+		cfg.Level = levelByStatus
+	}
+
+	skipPaths := toSet(cfg.SkipPaths, false)
+	allowHeaders := toSet(cfg.AllowHeaders, true)
+	redactHeaders := toSet(cfg.RedactHeaders, true)
+
 	return func(c *gin.Context) {
-		// Start timer
-		startTime := time.Now()
+		if skipPaths[c.Request.URL.Path] || skipPaths[c.FullPath()] || (cfg.Skipper != nil && cfg.Skipper(c)) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var reqBody []byte
+		if cfg.RequestBody && c.Request.Body != nil && isLoggedContentType(c.ContentType(), cfg.BodyContentTypes) {
+			reqBody = peekBody(c.Request, cfg.MaxBodySize)
+		}
+
+		var respWriter *bodyLogWriter
+		if cfg.ResponseBody {
+			respWriter = &bodyLogWriter{ResponseWriter: c.Writer, limit: cfg.MaxBodySize}
+			c.Writer = respWriter
+		}
 
-		// Process request
 		c.Next()
 
-		// Stop timer
-		endTime := time.Now()
-		latency := endTime.Sub(startTime)
+		latency := time.Since(start)
+		status := c.Writer.Status()
+
+		if status < http.StatusBadRequest && !sampled(c, cfg.Sampling) {
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+			slog.Int("bytes_out", max(c.Writer.Size(), 0)),
+		}
+
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			attrs = append(attrs,
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+		}
+		if rid := core.GetRequestID(ctx); rid != "" {
+			attrs = append(attrs, slog.String("request_id", rid))
+		}
+
+		if !cfg.SkipHeaders {
+			attrs = append(attrs, headerAttrs(c.Request.Header, allowHeaders, redactHeaders))
+		}
+		if reqBody != nil {
+			attrs = append(attrs, slog.String("request_body", string(reqBody)))
+		}
+		if respWriter != nil && isLoggedContentType(c.Writer.Header().Get("Content-Type"), cfg.BodyContentTypes) {
+			attrs = append(attrs, slog.String("response_body", respWriter.body.String()))
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			attrs = append(attrs, slog.String("error", errs.String()))
+		}
+
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.LogAttrs(ctx, cfg.Level(c), "Request", attrs...)
+	}
+}
+
+func levelByStatus(c *gin.Context) slog.Level {
+	switch status := c.Writer.Status(); {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// sampled reports whether a request of a sampled route is logged.
+func sampled(c *gin.Context, sampling map[string]float64) bool {
+	if len(sampling) == 0 {
+		return true
+	}
+
+	route := c.FullPath()
+	rate, ok := sampling[c.Request.Method+" "+route]
+	if !ok {
+		if rate, ok = sampling[route]; !ok {
+			return true
+		}
+	}
+
+	return rand.Float64() < rate
+}
 
-		// Get http status code
-		httpCode := c.Writer.Status()
+// headerAttrs groups the logged headers, multiple values are joined with a comma.
+func headerAttrs(header http.Header, allow, redact map[string]bool) slog.Attr {
+	attrs := make([]any, 0, len(header))
+	for name, values := range header {
+		if len(allow) > 0 && !allow[name] {
+			continue
+		}
 
-		// Get client IP
-		clientIP := c.ClientIP()
+		value := strings.Join(values, ", ")
+		if redact[name] {
+			value = redactedValue
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+
+	return slog.Group("header", attrs...)
+}
+
+// peekBody reads up to limit bytes of the request body, the handlers still read the whole body.
+func peekBody(r *http.Request, limit int) []byte {
+	buf := make([]byte, limit)
+	n, err := io.ReadFull(r.Body, buf)
+	buf = buf[:n]
 
-		// Get request method
-		method := c.Request.Method
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.Body = readCloser{Reader: bytes.NewReader(buf), Closer: r.Body}
+	} else {
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(buf), r.Body), Closer: r.Body}
+	}
+
+	return buf
+}
 
-		// Get request path
-		path := c.Request.URL.Path
+func isLoggedContentType(contentType string, allowed []string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+
+	for _, prefix := range allowed {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toSet builds a lookup set, header names are canonicalized.
+func toSet(values []string, header bool) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		if header {
+			v = http.CanonicalHeaderKey(v)
+		}
+		set[v] = true
+	}
+	return set
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyLogWriter keeps the first limit bytes written to the response.
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	if room := w.limit - w.body.Len(); room > 0 {
+		w.body.WriteString(s[:min(len(s), room)])
+	}
+	return w.ResponseWriter.WriteString(s)
+}
 
-		// parsing header to object
-		slog.Info("Request",
-			slog.String("client_ip", clientIP),
-			slog.Int("http_code", httpCode),
-			slog.String("method", method),
-			slog.String("path", path),
-			slog.String("latency", fmt.Sprintf("%v", latency)),
-			slog.String("header", fmt.Sprintf("%v", c.Request.Header)),
-		)
+func (w *bodyLogWriter) capture(b []byte) {
+	if room := w.limit - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
 	}
 }
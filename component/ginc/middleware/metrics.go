@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// unmatchedRoute labels requests matching no route, so unknown paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// DefaultDurationBuckets are the latency histogram buckets in seconds.
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// MetricsConfig configures the HTTP metrics middleware.
+type MetricsConfig struct {
+	// MeterProvider provides the instruments. Default the global meter provider.
+	MeterProvider metric.MeterProvider
+	// SkipPaths are not measured, matched against the request path and the route template, ex: /livez.
+	SkipPaths []string
+	// Skipper, when set, skips the requests it returns true for.
+	Skipper func(c *gin.Context) bool
+	// DurationBuckets are the latency histogram buckets in seconds. Default DefaultDurationBuckets.
+	DurationBuckets []float64
+}
+
+// Metrics records the RED metrics of requests with the global meter provider.
+func Metrics() gin.HandlerFunc {
+	return MetricsWithConfig(MetricsConfig{})
+}
+
+// MetricsWithConfig records for each request, labeled by route template (http.route),
+// method (http.request.method) and status class (http.response.status_class, ex: 2xx):
+//   - http.server.requests, the number of requests
+//   - http.server.errors, the number of 5xx responses
+//   - http.server.request.duration, the latency histogram in seconds
+//
+// and http.server.active_requests, the requests in flight by route and method.
+// Place it first so the responses of the other middlewares are measured.
+func MetricsWithConfig(cfg MetricsConfig) gin.HandlerFunc {
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+	if len(cfg.DurationBuckets) == 0 {
+		cfg.DurationBuckets = DefaultDurationBuckets
+	}
+
+	meter := cfg.MeterProvider.Meter(instrumentationName)
+
+	requests, err := meter.Int64Counter(
+		"http.server.requests",
+		metric.WithDescription("Number of HTTP requests handled."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	errs, err := meter.Int64Counter(
+		"http.server.errors",
+		metric.WithDescription("Number of HTTP requests answered with a 5xx status."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	duration, err := meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithDescription("Duration of HTTP requests."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(cfg.DurationBuckets...),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	active, err := meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Number of HTTP requests in flight."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	skipPaths := toSet(cfg.SkipPaths, false)
+
+	return func(c *gin.Context) {
+		if skipPaths[c.Request.URL.Path] || skipPaths[c.FullPath()] || (cfg.Skipper != nil && cfg.Skipper(c)) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		ctx := c.Request.Context()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		method := metricMethod(c.Request.Method)
+		activeAttrs := metric.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", route),
+		)
+		active.Add(ctx, 1, activeAttrs)
+		defer active.Add(ctx, -1, activeAttrs)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := metric.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", route),
+			attribute.String("http.response.status_class", statusClass(status)),
+		)
+
+		requests.Add(ctx, 1, attrs)
+		if status >= http.StatusInternalServerError {
+			errs.Add(ctx, 1, attrs)
+		}
+		duration.Record(ctx, time.Since(start).Seconds(), attrs)
+	}
+}
+
+// metricMethod returns the method, or _OTHER for non standard methods sent by clients.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "_OTHER"
+}
+
+// statusClass returns the class of a status code, ex: 2xx.
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
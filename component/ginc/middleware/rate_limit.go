@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+type RateLimitAlgorithm string
+
+const (
+	// RateLimitTokenBucket refills Limit tokens per Window and allows bursts up to Burst.
+	RateLimitTokenBucket RateLimitAlgorithm = "token-bucket"
+	// RateLimitSlidingWindow counts requests in a window sliding over the previous one.
+	RateLimitSlidingWindow RateLimitAlgorithm = "sliding-window"
+)
+
+const defaultRateLimitKeyPrefix = "ratelimit"
+
+// RateLimitRule allows Limit requests per Window.
+type RateLimitRule struct {
+	// Algorithm is the limiting algorithm. Default token bucket.
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	// Burst is the token bucket capacity. Default Limit.
+	Burst int
+}
+
+// RateLimitResult is the outcome of consuming one request.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time to wait before the next request is allowed, when not allowed.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the quota is fully available again.
+	ResetAfter time.Duration
+}
+
+// RateLimitStore keeps the limiter state, see NewMemoryRateLimitStore and NewRedisRateLimitStore.
+type RateLimitStore interface {
+	// Take consumes one request for key under the rule.
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc identifies the client of a request.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitConfig configures the rate limit middleware.
+type RateLimitConfig struct {
+	// Store keeps the limiter state. Default in-memory, use the Redis store when running several instances.
+	Store RateLimitStore
+	// KeyFunc identifies the client. Default KeyByIP.
+	KeyFunc RateLimitKeyFunc
+	// Rule is the default limit.
+	Rule RateLimitRule
+	// Routes overrides the rule per route template, keyed by "METHOD /path/:param" or "/path/:param".
+	// Each route has its own quota.
+	Routes map[string]RateLimitRule
+	// KeyPrefix namespaces the keys in the store. Default "ratelimit".
+	KeyPrefix string
+}
+
+// RateLimit limits requests per client IP with an in-memory store.
+func RateLimit(rule RateLimitRule) gin.HandlerFunc {
+	return RateLimitWithConfig(RateLimitConfig{Rule: rule})
+}
+
+// RateLimitWithConfig rejects clients over their quota with 429, Retry-After and RateLimit-* headers.
+// When the store fails, requests are let through and the error is logged.
+func RateLimitWithConfig(cfg RateLimitConfig) gin.HandlerFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore()
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = KeyByIP
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = defaultRateLimitKeyPrefix
+	}
+
+	cfg.Rule = normalizeRateLimitRule(cfg.Rule)
+	routes := make(map[string]RateLimitRule, len(cfg.Routes))
+	for route, rule := range cfg.Routes {
+		routes[route] = normalizeRateLimitRule(rule)
+	}
+
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		rule, scope := cfg.Rule, ""
+		if route := c.FullPath(); route != "" {
+			if r, ok := routes[c.Request.Method+" "+route]; ok {
+				rule, scope = r, c.Request.Method+" "+route
+			} else if r, ok := routes[route]; ok {
+				rule, scope = r, route
+			}
+		}
+
+		if rule.Limit <= 0 {
+			c.Next()
+			return
+		}
+
+		client := cfg.KeyFunc(c)
+		key := cfg.KeyPrefix + ":" + client
+		if scope != "" {
+			key = cfg.KeyPrefix + ":" + scope + ":" + client
+		}
+
+		res, err := cfg.Store.Take(c.Request.Context(), key, rule)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "rate limit store error", "error", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+
+		if !res.Allowed {
+			header.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+			core.WriteStandardErrorResponse(c, http.StatusTooManyRequests, ec.CustomError(
+				"TOO_MANY_REQUESTS",
+				"",
+				"Too many requests, please try again later",
+				"rate limit exceeded for "+key,
+			))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// KeyByIP identifies clients by IP, see gin.Engine.TrustedProxies for requests behind proxies.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByRequester identifies clients by the core.Requester subject, anonymous requests by IP.
+func KeyByRequester(c *gin.Context) string {
+	if r := core.GetRequester(c.Request.Context()); r != nil && r.GetSubject() != "" {
+		return "sub:" + r.GetSubject()
+	}
+	return KeyByIP(c)
+}
+
+// KeyByHeader identifies clients by a header such as an API key, requests without it by IP.
+// The value is hashed (SHA-256), credentials are not kept in the store keys nor in responses.
+func KeyByHeader(name string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if v := c.GetHeader(name); v != "" {
+			sum := sha256.Sum256([]byte(v))
+			return "key:" + hex.EncodeToString(sum[:])
+		}
+		return KeyByIP(c)
+	}
+}
+
+// normalizeRateLimitRule sets the defaults of a rule, it panics on a window under 1ms:
+// the stores count in milliseconds.
+func normalizeRateLimitRule(rule RateLimitRule) RateLimitRule {
+	if rule.Window > 0 && rule.Window < time.Millisecond {
+		panic(fmt.Sprintf("rate limit: window %s is under 1ms", rule.Window))
+	}
+	if rule.Algorithm == "" {
+		rule.Algorithm = RateLimitTokenBucket
+	}
+	if rule.Window <= 0 {
+		rule.Window = time.Second
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Limit
+	}
+	return rule
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
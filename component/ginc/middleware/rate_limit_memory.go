@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepInterval is how often expired entries are removed from the memory store.
+const rateLimitSweepInterval = time.Minute
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitEntry struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	// sliding window
+	windowStart time.Time
+	current     int
+	previous    int
+
+	expireAt time.Time
+}
+
+// NewMemoryRateLimitStore creates a rate limit store local to the process, for single instance services.
+func NewMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{
+		entries:   make(map[string]*rateLimitEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &rateLimitEntry{tokens: float64(rule.Burst), last: now, windowStart: now.Truncate(rule.Window)}
+		s.entries[key] = e
+	}
+
+	var res RateLimitResult
+	if rule.Algorithm == RateLimitSlidingWindow {
+		res = e.takeSlidingWindow(now, rule)
+	} else {
+		res = e.takeTokenBucket(now, rule)
+	}
+
+	e.expireAt = now.Add(res.ResetAfter)
+
+	return res, nil
+}
+
+// sweep removes the entries whose quota is fully available again.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if now.After(e.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (e *rateLimitEntry) takeTokenBucket(now time.Time, rule RateLimitRule) RateLimitResult {
+	rate := float64(rule.Limit) / rule.Window.Seconds() // tokens per second
+	burst := float64(rule.Burst)
+
+	e.tokens = math.Min(burst, e.tokens+now.Sub(e.last).Seconds()*rate)
+	e.last = now
+
+	res := RateLimitResult{Limit: rule.Burst}
+
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - e.tokens) / rate)
+	}
+
+	res.Remaining = int(e.tokens)
+	res.ResetAfter = secondsToDuration((burst - e.tokens) / rate)
+
+	return res
+}
+
+func (e *rateLimitEntry) takeSlidingWindow(now time.Time, rule RateLimitRule) RateLimitResult {
+	window := rule.Window
+	start := now.Truncate(window)
+
+	if !e.windowStart.Equal(start) {
+		if start.Sub(e.windowStart) == window {
+			e.previous = e.current
+		} else {
+			e.previous = 0
+		}
+		e.current = 0
+		e.windowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := float64(window-elapsed) / float64(window)
+	count := float64(e.previous)*weight + float64(e.current)
+
+	res := RateLimitResult{Limit: rule.Limit}
+
+	if count+1 <= float64(rule.Limit) {
+		e.current++
+		count++
+		res.Allowed = true
+	} else if e.current+1 > rule.Limit || e.previous == 0 {
+		res.RetryAfter = window - elapsed
+	} else {
+		// wait until the previous window weight decays enough
+		needed := time.Duration(float64(window) * (1 - float64(rule.Limit-e.current-1)/float64(e.previous)))
+		res.RetryAfter = max(needed-elapsed, 0)
+	}
+
+	res.Remaining = max(int(float64(rule.Limit)-count), 0)
+	res.ResetAfter = window - elapsed
+	if e.current > 0 {
+		res.ResetAfter += window
+	}
+
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes a token atomically, using the Redis clock
+// so that every instance shares the same time.
+// KEYS[1] bucket key; ARGV: rate (tokens/ms), burst, ttl (ms)
+// Returns {allowed, remaining, retry after (ms), reset after (ms)}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+
+return {allowed, math.floor(tokens), retry, math.ceil((burst - tokens) / rate)}
+`)
+
+// slidingWindowScript counts a request in the current window, weighting the previous one.
+// KEYS[1] window key; ARGV: limit, window (ms)
+// Returns {allowed, remaining, retry after (ms), reset after (ms)}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local start = now - (now % window)
+
+local state = redis.call('HMGET', KEYS[1], 'start', 'cur', 'prev')
+local prevStart = tonumber(state[1]) or start
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+
+if prevStart ~= start then
+	if start - prevStart == window then
+		prev = cur
+	else
+		prev = 0
+	end
+	cur = 0
+end
+
+local elapsed = now - start
+local count = prev * (window - elapsed) / window + cur
+
+local allowed = 0
+local retry = 0
+if count + 1 <= limit then
+	cur = cur + 1
+	count = count + 1
+	allowed = 1
+elseif cur + 1 > limit or prev == 0 then
+	retry = window - elapsed
+else
+	retry = math.max(0, math.ceil(window * (1 - (limit - cur - 1) / prev)) - elapsed)
+end
+
+redis.call('HSET', KEYS[1], 'start', start, 'cur', cur, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], window * 2)
+
+local reset = window - elapsed
+if cur > 0 then
+	reset = reset + window
+end
+
+return {allowed, math.max(0, math.floor(limit - count)), retry, reset}
+`)
+
+type redisRateLimitStore struct {
+	client redis.Scripter
+}
+
+// NewRedisRateLimitStore creates a rate limit store shared by every instance, the client
+// is usually the redisc component client. Scripts touch a single key wrapped in a hash tag,
+// so they are safe on Redis Cluster.
+func NewRedisRateLimitStore(client redis.Scripter) *redisRateLimitStore {
+	return &redisRateLimitStore{client: client}
+}
+
+func (s *redisRateLimitStore) Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitResult, error) {
+	// hash tag: the whole key decides the cluster slot
+	key = "{" + key + "}"
+
+	var (
+		values []int64
+		err    error
+		limit  int
+	)
+
+	windowMs := rule.Window.Milliseconds()
+
+	if rule.Algorithm == RateLimitSlidingWindow {
+		limit = rule.Limit
+		values, err = slidingWindowScript.Run(ctx, s.client, []string{key}, rule.Limit, windowMs).Int64Slice()
+	} else {
+		limit = rule.Burst
+		rate := float64(rule.Limit) / float64(windowMs)
+		ttl := int64(float64(rule.Burst)/rate) + 1000
+		values, err = tokenBucketScript.Run(ctx, s.client, []string{key}, rate, rule.Burst, ttl).Int64Slice()
+	}
+
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if len(values) != 4 {
+		return RateLimitResult{}, fmt.Errorf("rate limit script returned %d values", len(values))
+	}
+
+	return RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
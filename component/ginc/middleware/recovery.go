@@ -4,48 +4,117 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/gin-gonic/gin"
-	sctx "github.com/taimaifika/service-context"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
 )
 
+const instrumentationName = "github.com/taimaifika/service-context/component/ginc/middleware"
+
 type CanGetStatusCode interface {
 	StatusCode() int
 }
 
-func Recovery(serviceCtx sctx.ServiceContext) gin.HandlerFunc {
+// PanicReporter receives every recovered panic with the stack of the panicking goroutine,
+// ex: to send it to an error tracker.
+type PanicReporter func(c *gin.Context, recovered any, stack []byte)
+
+// RecoveryConfig configures the recovery middleware.
+type RecoveryConfig struct {
+	// Reporter, when set, is called after the panic is logged.
+	Reporter PanicReporter
+	// MeterProvider provides the panic counter. Default the global meter provider.
+	MeterProvider metric.MeterProvider
+}
+
+// Recovery recovers from panics with the default configuration.
+func Recovery() gin.HandlerFunc {
+	return RecoveryWithConfig(RecoveryConfig{})
+}
+
+// RecoveryWithConfig recovers from panics in the next handlers. The panic is logged with its stack,
+// recorded as an exception event on the request span, counted by route (http.server.panics)
+// and answered with the standard error envelope: the status of the panic value when it carries one
+// (core.StatusCodeCarrier), 500 otherwise. http.ErrAbortHandler is re-panicked for net/http.
+func RecoveryWithConfig(cfg RecoveryConfig) gin.HandlerFunc {
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	panics, err := cfg.MeterProvider.Meter(instrumentationName).Int64Counter(
+		"http.server.panics",
+		metric.WithDescription("Number of panics recovered while handling requests."),
+		metric.WithUnit("{panic}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	ec := mwutil.ErrorContext()
+
 	return func(c *gin.Context) {
 		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			if p, ok := recovered.(*handlerPanic); ok {
+				recovered, stack = p.value, p.stack
+			}
 
-			if err := recover(); err != nil {
-				// OpenTelemetry
-				ctx, span := otel.Tracer("gin.middleware.recovery").Start(c, "Recovery")
-				span.SetStatus(codes.Error, "Gin middleware recovered")
-				span.SetAttributes(attribute.String("gin.middleware.recovery.url", c.Request.URL.String()))
-				span.SetAttributes(attribute.String("gin.middleware.recovery.method", c.Request.Method))
-				span.SetAttributes(attribute.String("gin.middleware.recovery.header", fmt.Sprintf("%+v\n", c.Request.Header)))
-				span.SetAttributes(attribute.String("gin.middleware.recovery.error", fmt.Sprintf("%+v\n", err)))
+			cause, ok := recovered.(error)
+			if !ok {
+				cause = fmt.Errorf("panic: %v", recovered)
+			}
+
+			ctx := c.Request.Context()
+			route := c.FullPath()
+
+			span := trace.SpanFromContext(ctx)
+			if !span.IsRecording() {
+				ctx, span = otel.Tracer(instrumentationName).Start(ctx, "Recovery")
 				defer span.End()
+			}
+			span.RecordError(cause, trace.WithAttributes(attribute.String("exception.stacktrace", string(stack))))
+			span.SetStatus(codes.Error, "panic recovered")
 
-				// Response with error
-				if appErr, ok := err.(CanGetStatusCode); ok {
-					slog.ErrorContext(ctx, "Gin middleware recovered", "error", appErr)
-					c.AbortWithStatusJSON(appErr.StatusCode(), appErr)
-				} else {
-					// General panic cases
-					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-						"code":    http.StatusInternalServerError,
-						"status":  "internal server error",
-						"message": "something went wrong, please try again or contact supporters",
-					})
-				}
-
-				slog.ErrorContext(ctx, "Gin middleware recovered", "error", err)
+			panics.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			))
+
+			slog.ErrorContext(ctx, "Panic recovered",
+				"error", cause.Error(),
+				"method", c.Request.Method,
+				"route", route,
+				"stack", string(stack),
+			)
+
+			if cfg.Reporter != nil {
+				cfg.Reporter(c, recovered, stack)
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
 			}
+
+			writeError(c, ec, toHTTPError(cause, nil, http.StatusInternalServerError, core.GetRequestID(ctx)), cause)
 		}()
+
 		c.Next()
 	}
 }
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	httpmw "github.com/taimaifika/service-context/component/httpserverc/middleware"
+)
+
+// RequestIDConfig configures the request ID middleware.
+type RequestIDConfig = httpmw.RequestIDConfig
+
+// RequestID reads the X-Request-ID header or generates a new ID, see RequestIDWithConfig.
+func RequestID() gin.HandlerFunc {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig reads the request ID from the header or generates a new one, stores it
+// in the request context (core.GetRequestID), echoes it in the response header and sets it
+// on the active span. Place it after the tracing middleware so the span exists.
+// It is the net/http middleware of httpserverc run through WrapHTTP. The ginc component installs
+// it before its timeout middleware, the ID it sets is kept when the middleware is used again.
+func RequestIDWithConfig(cfg RequestIDConfig) gin.HandlerFunc {
+	return WrapHTTP(httpmw.RequestIDWithConfig(cfg))
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	SecurityPresetAPI = "api"
+	SecurityPresetWeb = "web"
+
+	// CSPNoncePlaceholder is replaced by the nonce of the request in the Content-Security-Policy.
+	CSPNoncePlaceholder = "{nonce}"
+
+	cspNonceKey        = "csp_nonce"
+	defaultHSTSMaxAge  = 365 * 24 * time.Hour
+	cspNonceRandomSize = 16
+)
+
+// SecurityHeadersConfig configures the security headers middleware, an empty field sends no header.
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is the Strict-Transport-Security max-age, 0 to disable.
+	// Browsers ignore it on plain HTTP, it is sent anyway since TLS is usually terminated by a proxy.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	// ContentTypeNosniff sends "X-Content-Type-Options: nosniff".
+	ContentTypeNosniff bool
+	// FrameOptions is the X-Frame-Options value, DENY or SAMEORIGIN.
+	FrameOptions   string
+	ReferrerPolicy string
+	// PermissionsPolicy lists the browser features allowed, ex: "camera=(), geolocation=(self)".
+	PermissionsPolicy string
+	// ContentSecurityPolicy may use the {nonce} placeholder, ex: "script-src 'self' 'nonce-{nonce}'",
+	// a new nonce is generated per request and returned by CSPNonce.
+	ContentSecurityPolicy string
+	// CSPReportOnly sends the policy as Content-Security-Policy-Report-Only to try it without enforcing.
+	CSPReportOnly bool
+	// CrossOriginOpenerPolicy is the Cross-Origin-Opener-Policy value, ex: same-origin.
+	CrossOriginOpenerPolicy string
+}
+
+// APISecurityHeadersConfig is the preset of JSON APIs: nothing may be rendered, framed or loaded.
+func APISecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:            defaultHSTSMaxAge,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		PermissionsPolicy:     "accelerometer=(), camera=(), geolocation=(), gyroscope=(), magnetometer=(), microphone=(), payment=(), usb=()",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+	}
+}
+
+// WebSecurityHeadersConfig is the preset of server-rendered pages: same-origin resources,
+// inline scripts and styles need the nonce of the request (CSPNonce).
+func WebSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		HSTSMaxAge:            defaultHSTSMaxAge,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "SAMEORIGIN",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		PermissionsPolicy:     "camera=(), geolocation=(), microphone=(), payment=(), usb=()",
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; " +
+			"img-src 'self' data:; object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'self'",
+		CrossOriginOpenerPolicy: "same-origin",
+	}
+}
+
+// SecurityHeadersPreset returns the config of a preset, api or web.
+func SecurityHeadersPreset(name string) (SecurityHeadersConfig, error) {
+	switch name {
+	case SecurityPresetAPI:
+		return APISecurityHeadersConfig(), nil
+	case SecurityPresetWeb:
+		return WebSecurityHeadersConfig(), nil
+	}
+	return SecurityHeadersConfig{}, errors.New("security headers: unknown preset " + name + ", expected api or web")
+}
+
+// Validate checks the config.
+func (cfg SecurityHeadersConfig) Validate() error {
+	if cfg.HSTSMaxAge < 0 {
+		return errors.New("security headers: HSTS max age must not be negative")
+	}
+	if cfg.HSTSPreload && (cfg.HSTSMaxAge < defaultHSTSMaxAge || !cfg.HSTSIncludeSubdomains) {
+		return errors.New("security headers: HSTS preload requires a max age of at least 1 year and subdomains")
+	}
+
+	switch strings.ToUpper(cfg.FrameOptions) {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		return errors.New("security headers: frame options must be DENY or SAMEORIGIN")
+	}
+
+	if strings.ContainsAny(cfg.ContentSecurityPolicy+cfg.PermissionsPolicy+cfg.ReferrerPolicy, "\r\n") {
+		return errors.New("security headers: header values must not contain line breaks")
+	}
+
+	return nil
+}
+
+// SecurityHeaders sets the headers of a preset, api or web. It panics on an unknown preset.
+func SecurityHeaders(preset string) gin.HandlerFunc {
+	cfg, err := SecurityHeadersPreset(preset)
+	if err != nil {
+		panic(err)
+	}
+	return SecurityHeadersWithConfig(cfg)
+}
+
+// SecurityHeadersWithConfig sets the security headers on every response before the handlers run,
+// so error responses get them too. It panics if the config is not valid.
+func SecurityHeadersWithConfig(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	// static headers are built once, only the CSP changes per request
+	static := make(map[string]string)
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge.Seconds()), 10)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+		static["Strict-Transport-Security"] = hsts
+	}
+	if cfg.ContentTypeNosniff {
+		static["X-Content-Type-Options"] = "nosniff"
+	}
+	if cfg.FrameOptions != "" {
+		static["X-Frame-Options"] = strings.ToUpper(cfg.FrameOptions)
+	}
+	if cfg.ReferrerPolicy != "" {
+		static["Referrer-Policy"] = cfg.ReferrerPolicy
+	}
+	if cfg.PermissionsPolicy != "" {
+		static["Permissions-Policy"] = cfg.PermissionsPolicy
+	}
+	if cfg.CrossOriginOpenerPolicy != "" {
+		static["Cross-Origin-Opener-Policy"] = cfg.CrossOriginOpenerPolicy
+	}
+
+	cspHeader := "Content-Security-Policy"
+	if cfg.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	csp := cfg.ContentSecurityPolicy
+	useNonce := strings.Contains(csp, CSPNoncePlaceholder)
+	if csp != "" && !useNonce {
+		static[cspHeader] = csp
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		for k, v := range static {
+			header.Set(k, v)
+		}
+
+		if useNonce {
+			nonce := newCSPNonce()
+			c.Set(cspNonceKey, nonce)
+			header.Set(cspHeader, strings.ReplaceAll(csp, CSPNoncePlaceholder, nonce))
+		}
+
+		c.Next()
+	}
+}
+
+// CSPNonce returns the Content-Security-Policy nonce of the request, to set on inline
+// <script nonce="..."> and <style nonce="..."> tags. It is empty when the policy has no nonce.
+func CSPNonce(c *gin.Context) string {
+	return c.GetString(cspNonceKey)
+}
+
+func newCSPNonce() string {
+	b := make([]byte, cspNonceRandomSize)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
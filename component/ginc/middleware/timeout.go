@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+var errTimeoutHijack = errors.New("timeout: hijacking is not supported, disable the timeout for this route")
+
+// TimeoutConfig configures the request timeout middleware.
+type TimeoutConfig struct {
+	// Timeout is the default deadline of a request, 0 for none.
+	Timeout time.Duration
+	// Routes overrides the timeout for the route templates starting with a prefix, keyed by
+	// "METHOD /prefix" or "/prefix", ex: "/v1/reports" or "POST /v1/uploads". Prefixes match whole
+	// path segments, the longest prefix wins, then the method specific key. 0 disables the timeout
+	// (streaming routes).
+	Routes map[string]time.Duration
+	// StatusCode answered when the deadline passes, 503 or 504. Default 504.
+	StatusCode int
+}
+
+// Timeout sets a deadline on every request.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: timeout})
+}
+
+// TimeoutWithConfig sets a deadline on the request context so downstream calls (databases, httpc)
+// are cancelled, and answers with the standard error body once it passes. The response of the
+// handlers is buffered until they return, writes after the deadline are discarded.
+// The next handlers run in their own goroutine and the middleware waits for them before returning,
+// panics are re-raised to the previous middlewares (Recovery). Streaming, SSE and WebSocket routes
+// must have no timeout.
+func TimeoutWithConfig(cfg TimeoutConfig) gin.HandlerFunc {
+	if cfg.StatusCode == 0 {
+		cfg.StatusCode = http.StatusGatewayTimeout
+	}
+
+	ec := mwutil.ErrorContext()
+
+	return func(c *gin.Context) {
+		timeout := routeTimeout(c, cfg)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		w := c.Writer
+		tw := &timeoutWriter{ResponseWriter: w, header: w.Header().Clone(), status: http.StatusOK}
+		c.Writer = tw
+
+		done := make(chan struct{})
+		var panicked any
+
+		go func() {
+			defer close(done)
+			defer func() {
+				if r := recover(); r != nil {
+					panicked = r
+					if r != http.ErrAbortHandler {
+						panicked = &handlerPanic{value: r, stack: debug.Stack()}
+					}
+				}
+			}()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// a cancelled request means the client is gone, there is no one to answer
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && tw.timeout(cfg.StatusCode) {
+				writeTimeout(w, ec, cfg.StatusCode, core.GetRequestID(ctx))
+			}
+			<-done
+		}
+
+		c.Writer = w
+
+		if panicked != nil {
+			panic(panicked)
+		}
+
+		if !tw.timedOut {
+			tw.flushTo(w)
+		}
+	}
+}
+
+// handlerPanic carries a panic of the handlers goroutine with its stack, see Recovery.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+func (p *handlerPanic) String() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+func routeTimeout(c *gin.Context, cfg TimeoutConfig) time.Duration {
+	if d, ok := routePrefixValue(c, cfg.Routes); ok {
+		return d
+	}
+	return cfg.Timeout
+}
+
+// routePrefixValue returns the value of the longest prefix matching the route template,
+// keys are "METHOD /prefix" or "/prefix", see matchRoutePrefix.
+func routePrefixValue[T any](c *gin.Context, routes map[string]T) (value T, found bool) {
+	return matchRoutePrefix(routes, c.Request.Method, c.FullPath())
+}
+
+// matchRoutePrefix returns the value of the longest prefix matching route on a path segment boundary,
+// "/v1/tasks" matches "/v1/tasks" and "/v1/tasks/:id" but not "/v1/tasks-archive". The method is not
+// counted in the length, on equal prefixes "METHOD /prefix" wins over "/prefix".
+func matchRoutePrefix[T any](routes map[string]T, method, route string) (value T, found bool) {
+	longest, specific := -1, false
+	for key, v := range routes {
+		prefix, hasMethod := key, false
+		if m, p, ok := strings.Cut(key, " "); ok {
+			if m != method {
+				continue
+			}
+			prefix, hasMethod = strings.TrimSpace(p), true
+		}
+
+		if !hasPathPrefix(route, prefix) {
+			continue
+		}
+		if len(prefix) > longest || len(prefix) == longest && hasMethod && !specific {
+			value, found, longest, specific = v, true, len(prefix), hasMethod
+		}
+	}
+
+	return value, found
+}
+
+// hasPathPrefix reports whether prefix is route or one of its parent paths.
+func hasPathPrefix(route, prefix string) bool {
+	if !strings.HasPrefix(route, prefix) {
+		return false
+	}
+	return len(route) == len(prefix) || strings.HasSuffix(prefix, "/") || route[len(prefix)] == '/'
+}
+
+// writeTimeout answers directly on the underlying writer, the gin context is still used by the handlers.
+func writeTimeout(w gin.ResponseWriter, ec *core.ErrorContext, status int, requestID string) {
+	de := core.ErrGatewayTimeout
+	if status == http.StatusServiceUnavailable {
+		de = core.ErrServiceUnavailable
+	}
+
+	resp := ec.CustomError(mwutil.ErrorCode(status), "", de.Error(), "request deadline exceeded")
+	resp.Error.RequestID = requestID
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		slog.Error("timeout: cannot encode response", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+	w.Flush()
+}
+
+// timeoutWriter buffers the response of the handlers until they return, it is discarded on timeout.
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	written  bool
+	timedOut bool
+}
+
+// timeout marks the response as timed out, it returns false when it was already timed out.
+func (w *timeoutWriter) timeout(status int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return false
+	}
+	w.timedOut = true
+	w.status = status
+	return true
+}
+
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	header := dst.Header()
+	for k := range header {
+		if _, ok := w.header[k]; !ok {
+			header.Del(k)
+		}
+	}
+	for k, v := range w.header {
+		header[k] = v
+	}
+
+	dst.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = dst.Write(w.body.Bytes())
+	} else {
+		dst.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.written || code <= 0 {
+		return
+	}
+	w.status = code
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.written || w.timedOut
+}
+
+// Flush is a no-op, the response is sent when the handlers return.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errTimeoutHijack
+}
+
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchRoutePrefix(t *testing.T) {
+	routes := map[string]time.Duration{
+		"/v1/tasks":     30 * time.Second,
+		"DELETE /v1":    2 * time.Second,
+		"/v1/reports/":  time.Minute,
+		"GET /v1/tasks": 5 * time.Second,
+		"/":             time.Second,
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		want   time.Duration
+		found  bool
+	}{
+		{"exact prefix", "POST", "/v1/tasks", 30 * time.Second, true},
+		{"child path", "POST", "/v1/tasks/:id", 30 * time.Second, true},
+		{"longest prefix beats method", "DELETE", "/v1/tasks/:id", 30 * time.Second, true},
+		{"method key on shorter route", "DELETE", "/v1/users/:id", 2 * time.Second, true},
+		{"method key wins tie", "GET", "/v1/tasks/:id", 5 * time.Second, true},
+		{"segment boundary", "POST", "/v1/tasks-archive", time.Second, true},
+		{"trailing slash prefix", "GET", "/v1/reports/daily", time.Minute, true},
+		{"root prefix", "GET", "/health", time.Second, true},
+		{"unmatched route", "GET", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := matchRoutePrefix(routes, tt.method, tt.route)
+			if got != tt.want || found != tt.found {
+				t.Errorf("matchRoutePrefix(%s %q) = %v, %v, want %v, %v", tt.method, tt.route, got, found, tt.want, tt.found)
+			}
+		})
+	}
+
+	if _, found := matchRoutePrefix(map[string]int{"/v1/task": 1}, "GET", "/v1/tasks"); found {
+		t.Error("/v1/task must not match /v1/tasks")
+	}
+}
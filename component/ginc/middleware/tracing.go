@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+)
+
+// spanNameKey carries the span name from the tracing middleware to the otelgin span name formatter.
+type spanNameKey struct{}
+
+// TracingConfig configures the tracing middleware.
+type TracingConfig struct {
+	// ServiceName is the name of the server in the span attributes, usually the service context name.
+	ServiceName string
+	// TracerProvider creates the spans. Default the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// Propagators extract the parent span from the request headers. Default the global propagators.
+	Propagators propagation.TextMapPropagator
+	// ExcludePaths are not traced, matched against the request path and the route template.
+	// A trailing * matches a prefix, ex: /debug/pprof/*.
+	ExcludePaths []string
+	// ExcludeMethods are not traced, ex: OPTIONS.
+	ExcludeMethods []string
+	// Skipper, when set, skips the requests it returns true for.
+	Skipper func(c *gin.Context) bool
+}
+
+// Tracing traces requests with the global tracer provider, see TracingWithConfig.
+func Tracing(serviceName string) gin.HandlerFunc {
+	return TracingWithConfig(TracingConfig{ServiceName: serviceName})
+}
+
+// TracingWithConfig starts a server span for each request with otelgin, named after the
+// method and route template (ex: GET /v1/tasks/:id) so spans of a route are grouped.
+// The span is in the request context: RequestID and the auth middlewares add the request ID
+// and the requester to it, place Tracing before them.
+// HTTP metrics are recorded by the Metrics middleware, not by otelgin.
+func TracingWithConfig(cfg TracingConfig) gin.HandlerFunc {
+	if cfg.ServiceName == "" {
+		panic("tracing: service name is required")
+	}
+
+	opts := []otelgin.Option{
+		otelgin.WithMeterProvider(noop.NewMeterProvider()),
+		otelgin.WithSpanNameFormatter(func(r *http.Request) string {
+			name, _ := r.Context().Value(spanNameKey{}).(string)
+			return name
+		}),
+	}
+	if cfg.TracerProvider != nil {
+		opts = append(opts, otelgin.WithTracerProvider(cfg.TracerProvider))
+	}
+	if cfg.Propagators != nil {
+		opts = append(opts, otelgin.WithPropagators(cfg.Propagators))
+	}
+
+	traced := otelgin.Middleware(cfg.ServiceName, opts...)
+	exclude := mwutil.NewMatcher(cfg.ExcludePaths)
+	excludeMethods := make(map[string]bool, len(cfg.ExcludeMethods))
+	for _, m := range cfg.ExcludeMethods {
+		excludeMethods[strings.ToUpper(m)] = true
+	}
+
+	return func(c *gin.Context) {
+		if excludeMethods[c.Request.Method] ||
+			exclude.Match(c.Request.URL.Path) || exclude.Match(c.FullPath()) ||
+			(cfg.Skipper != nil && cfg.Skipper(c)) {
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), spanNameKey{}, spanName(c)))
+		traced(c)
+	}
+}
+
+// spanName returns the method and route template, only the method for unmatched routes
+// so unknown paths do not create new span names.
+func spanName(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return c.Request.Method + " " + route
+	}
+	return c.Request.Method
+}
@@ -0,0 +1,466 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/ginc"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+	"github.com/taimaifika/service-context/core"
+)
+
+// Handler is a typed handler: the request is bound and validated with ginc.Bind,
+// the response is rendered in the core.StandardResponse envelope and errors are
+// left to middleware.ErrorHandler, or rendered with middleware.WriteError when it is not installed.
+type Handler[Req, Resp any] func(c *gin.Context, req *Req) (Resp, error)
+
+// NoContent is the response of handlers answering 204 without body.
+type NoContent struct{}
+
+// spec is the document shared by an API and its groups.
+type spec struct {
+	mu  sync.Mutex
+	doc *Document
+	gen *schemaGenerator
+}
+
+// API registers typed handlers on a gin router and documents them.
+//
+//	api := openapi.New(router, openapi.Info{Title: "Tasks", Version: "1.0.0"})
+//	tasks := api.Group("/tasks").WithTags("tasks")
+//	openapi.Get(tasks, "/:id", getTaskHdl, openapi.Summary("Get a task"), openapi.Errors(http.StatusNotFound))
+//	api.Serve(router, openapi.ServeConfig{UI: openapi.UISwagger})
+type API struct {
+	spec     *spec
+	router   gin.IRouter
+	tags     []string
+	security []SecurityRequirement
+}
+
+// New creates an API registering its routes on router.
+func New(router gin.IRouter, info Info) *API {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+	}
+
+	gen := newSchemaGenerator(doc.Components.Schemas)
+	doc.Components.Schemas["ErrorResponse"] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":  {Type: "string", Description: "action directive, ex: REDIRECT_HOME"},
+			"error": gen.schemaOf(reflect.TypeOf(core.ErrorDetail{})),
+		},
+		Required: []string{"error"},
+	}
+
+	return &API{spec: &spec{doc: doc, gen: gen}, router: router}
+}
+
+// Group creates a route group sharing the document, see gin.RouterGroup.Group.
+func (a *API) Group(relativePath string, handlers ...gin.HandlerFunc) *API {
+	g := *a
+	g.router = a.router.Group(relativePath, handlers...)
+	return &g
+}
+
+// WithTags returns the API tagging the operations registered from it.
+func (a *API) WithTags(tags ...string) *API {
+	g := *a
+	g.tags = append(append([]string{}, a.tags...), tags...)
+	return &g
+}
+
+// WithSecurity returns the API requiring one of the security schemes on the operations registered from it.
+func (a *API) WithSecurity(schemes ...string) *API {
+	g := *a
+	g.security = append(append([]SecurityRequirement{}, a.security...), securityRequirements(schemes)...)
+	return &g
+}
+
+// AddSecurityScheme declares a security scheme, ex: api.AddSecurityScheme("bearerAuth", openapi.BearerAuth()).
+func (a *API) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	a.spec.mu.Lock()
+	defer a.spec.mu.Unlock()
+
+	a.spec.doc.Components.SecuritySchemes[name] = scheme
+}
+
+// AddServer adds a server URL to the document.
+func (a *API) AddServer(url, description string) {
+	a.spec.mu.Lock()
+	defer a.spec.mu.Unlock()
+
+	a.spec.doc.Servers = append(a.spec.doc.Servers, Server{URL: url, Description: description})
+}
+
+// MarshalJSON encodes the document.
+func (a *API) MarshalJSON() ([]byte, error) {
+	a.spec.mu.Lock()
+	defer a.spec.mu.Unlock()
+
+	return json.MarshalIndent(a.spec.doc, "", "  ")
+}
+
+// WriteFile writes the document to a file, "-" for stdout.
+func (a *API) WriteFile(name string) error {
+	b, err := a.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	if name == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(name, b, 0o644)
+}
+
+// Option configures an operation.
+type Option func(*operationConfig)
+
+type operationConfig struct {
+	op          *Operation
+	status      int
+	errors      []int
+	middlewares []gin.HandlerFunc
+}
+
+// Summary sets the short summary of the operation.
+func Summary(s string) Option {
+	return func(oc *operationConfig) { oc.op.Summary = s }
+}
+
+// Description sets the description of the operation, markdown is allowed.
+func Description(s string) Option {
+	return func(oc *operationConfig) { oc.op.Description = s }
+}
+
+// OperationID overrides the operation ID, the default is the method and the path, ex: get_tasks_id.
+func OperationID(id string) Option {
+	return func(oc *operationConfig) { oc.op.OperationID = id }
+}
+
+// Tags adds tags to the operation.
+func Tags(tags ...string) Option {
+	return func(oc *operationConfig) { oc.op.Tags = append(oc.op.Tags, tags...) }
+}
+
+// Security requires one of the security schemes.
+func Security(schemes ...string) Option {
+	return func(oc *operationConfig) { oc.op.Security = append(oc.op.Security, securityRequirements(schemes)...) }
+}
+
+// Deprecated marks the operation as deprecated.
+func Deprecated() Option {
+	return func(oc *operationConfig) { oc.op.Deprecated = true }
+}
+
+// Status sets the success status. Default 201 for POST, 204 for NoContent responses and 200 otherwise.
+func Status(code int) Option {
+	return func(oc *operationConfig) { oc.status = code }
+}
+
+// Errors documents the error statuses returned by the handler, 400 and 500 are always documented.
+func Errors(codes ...int) Option {
+	return func(oc *operationConfig) { oc.errors = append(oc.errors, codes...) }
+}
+
+// Middlewares runs handlers before the operation handler, ex: middleware.Idempotency().
+func Middlewares(handlers ...gin.HandlerFunc) Option {
+	return func(oc *operationConfig) { oc.middlewares = append(oc.middlewares, handlers...) }
+}
+
+// Get registers a typed GET handler, see Handle.
+func Get[Req, Resp any](a *API, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	Handle(a, http.MethodGet, relativePath, h, opts...)
+}
+
+// Post registers a typed POST handler, see Handle.
+func Post[Req, Resp any](a *API, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	Handle(a, http.MethodPost, relativePath, h, opts...)
+}
+
+// Put registers a typed PUT handler, see Handle.
+func Put[Req, Resp any](a *API, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	Handle(a, http.MethodPut, relativePath, h, opts...)
+}
+
+// Patch registers a typed PATCH handler, see Handle.
+func Patch[Req, Resp any](a *API, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	Handle(a, http.MethodPatch, relativePath, h, opts...)
+}
+
+// Delete registers a typed DELETE handler, see Handle.
+func Delete[Req, Resp any](a *API, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	Handle(a, http.MethodDelete, relativePath, h, opts...)
+}
+
+// Handle registers a typed handler and documents its operation. Path parameters, query,
+// headers and body are read from the uri, form, header and json tags of Req,
+// the binding tags become schema constraints and the doc tags descriptions.
+// It panics when the operation is already documented.
+func Handle[Req, Resp any](a *API, method, relativePath string, h Handler[Req, Resp], opts ...Option) {
+	reqType := reflect.TypeOf((*Req)(nil)).Elem()
+	respType := reflect.TypeOf((*Resp)(nil)).Elem()
+
+	oc := &operationConfig{op: &Operation{
+		Tags:     append([]string{}, a.tags...),
+		Security: append([]SecurityRequirement{}, a.security...),
+	}}
+	switch {
+	case respType == reflect.TypeOf(NoContent{}):
+		oc.status = http.StatusNoContent
+	case method == http.MethodPost:
+		oc.status = http.StatusCreated
+	default:
+		oc.status = http.StatusOK
+	}
+	for _, opt := range opts {
+		opt(oc)
+	}
+
+	fullPath := joinPaths(basePath(a.router), relativePath)
+	a.spec.document(method, fullPath, reqType, respType, oc)
+
+	status := oc.status
+	handler := func(c *gin.Context) {
+		req, err := ginc.Bind[Req](c)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+
+		resp, err := h(c, req)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+
+		// the handler wrote its own response, ex: a file or a redirect
+		if c.Writer.Written() {
+			return
+		}
+
+		if status == http.StatusNoContent {
+			c.Status(status)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		c.JSON(status, core.NewSuccessResponse(resp))
+	}
+
+	a.router.Handle(method, relativePath, append(oc.middlewares, handler)...)
+}
+
+// fail leaves err to middleware.ErrorHandler, the error is rendered directly when the route has none
+// so the client never gets an empty 200.
+func fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+	if !middleware.HasErrorHandler(c) && !c.Writer.Written() {
+		middleware.WriteError(c, err)
+	}
+	c.Abort()
+}
+
+// document adds the operation of a route to the document.
+func (s *spec) document(method, fullPath string, reqType, respType reflect.Type, oc *operationConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := oc.op
+	oapiPath := toOpenAPIPath(fullPath)
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, fullPath)
+	}
+
+	op.Parameters = s.parameters(reqType)
+	if ginc.MethodHasBody(method) {
+		op.RequestBody = s.requestBody(reqType)
+	}
+
+	op.Responses = make(map[string]*Response)
+	success := &Response{Description: http.StatusText(oc.status)}
+	if oc.status != http.StatusNoContent {
+		success.Content = jsonContent(&Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"code": {Type: "string", Description: "action directive, ex: REDIRECT_HOME"},
+				"data": s.gen.schemaOf(respType),
+			},
+			Required: []string{"data"},
+		})
+	}
+	op.Responses[fmt.Sprint(oc.status)] = success
+
+	errs := []int{http.StatusInternalServerError}
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		errs = append(errs, http.StatusBadRequest)
+	}
+	if len(op.Security) > 0 {
+		errs = append(errs, http.StatusUnauthorized)
+	}
+	for _, code := range append(errs, oc.errors...) {
+		op.Responses[fmt.Sprint(code)] = &Response{
+			Description: http.StatusText(code),
+			Content:     jsonContent(&Schema{Ref: "#/components/schemas/ErrorResponse"}),
+		}
+	}
+
+	item, ok := s.doc.Paths[oapiPath]
+	if !ok {
+		item = &PathItem{}
+		s.doc.Paths[oapiPath] = item
+	}
+
+	key := strings.ToLower(method)
+	if _, exists := (*item)[key]; exists {
+		panic("openapi: operation " + method + " " + oapiPath + " is already documented")
+	}
+	(*item)[key] = op
+}
+
+// parameters returns the path, query and header parameters of the uri, form and header tags.
+func (s *spec) parameters(t reflect.Type) []*Parameter {
+	var params []*Parameter
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous && f.Tag.Get("uri") == "" && f.Tag.Get("form") == "" && f.Tag.Get("header") == "" {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+
+			for _, in := range []struct{ tag, in string }{{"uri", "path"}, {"form", "query"}, {"header", "header"}} {
+				name, opts, _ := strings.Cut(f.Tag.Get(in.tag), ",")
+				if name == "" || name == "-" {
+					continue
+				}
+
+				schema := s.gen.schemaOf(f.Type)
+				required := applyBindingRules(schema, f.Tag.Get("binding"))
+				if def, ok := strings.CutPrefix(opts, "default="); ok {
+					schema.Default = enumValue(schema, def)
+				}
+
+				params = append(params, &Parameter{
+					Name:        name,
+					In:          in.in,
+					Description: f.Tag.Get("doc"),
+					Required:    required || in.in == "path",
+					Schema:      schema,
+				})
+				break
+			}
+		}
+	}
+	walk(t)
+
+	return params
+}
+
+// requestBody returns the JSON body of the fields without uri, form or header tags, nil when there is none.
+func (s *spec) requestBody(t reflect.Type) *RequestBody {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return &RequestBody{Required: true, Content: jsonContent(s.gen.schemaOf(t))}
+	}
+
+	schema := s.gen.structSchema(t, isBodyField)
+	if len(schema.Properties) == 0 {
+		return nil
+	}
+
+	return &RequestBody{Required: len(schema.Required) > 0, Content: jsonContent(schema)}
+}
+
+func isBodyField(f reflect.StructField) bool {
+	for _, tag := range []string{"uri", "form", "header"} {
+		if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: s}}
+}
+
+func securityRequirements(schemes []string) []SecurityRequirement {
+	reqs := make([]SecurityRequirement, len(schemes))
+	for i, name := range schemes {
+		reqs[i] = SecurityRequirement{name: {}}
+	}
+	return reqs
+}
+
+// toOpenAPIPath converts the gin parameters :id and *path to {id} and {path}.
+func toOpenAPIPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationID(method, p string) string {
+	id := strings.ToLower(method)
+	for _, seg := range strings.Split(p, "/") {
+		seg = strings.Trim(seg, ":*{}")
+		if seg != "" {
+			id += "_" + strings.NewReplacer("-", "_", ".", "_").Replace(seg)
+		}
+	}
+	return id
+}
+
+func basePath(r gin.IRouter) string {
+	if b, ok := r.(interface{ BasePath() string }); ok {
+		return b.BasePath()
+	}
+	return "/"
+}
+
+// joinPaths joins paths as gin does, keeping the trailing slash of the relative path.
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	joined := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
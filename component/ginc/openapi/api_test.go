@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+	"github.com/taimaifika/service-context/core"
+)
+
+type createTaskRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type taskResponse struct {
+	Title string `json:"title"`
+}
+
+func TestHandleErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		errorHandler bool
+		body         string
+		handlerErr   error
+		wantStatus   int
+	}{
+		{"validation without error handler", false, `{}`, nil, http.StatusBadRequest},
+		{"validation with error handler", true, `{}`, nil, http.StatusBadRequest},
+		{"handler error without error handler", false, `{"title":"a"}`, core.ErrNotFound, http.StatusNotFound},
+		{"internal error without error handler", false, `{"title":"a"}`, errors.New("boom"), http.StatusInternalServerError},
+		{"success", false, `{"title":"a"}`, nil, http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if tt.errorHandler {
+				router.Use(middleware.ErrorHandler())
+			}
+
+			api := New(router, Info{Title: "test", Version: "1.0.0"})
+			Post(api, "/tasks", func(_ *gin.Context, req *createTaskRequest) (taskResponse, error) {
+				return taskResponse{Title: req.Title}, tt.handlerErr
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			var resp core.StandardResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode body %q: %v", w.Body.String(), err)
+			}
+			if hasError := tt.wantStatus >= http.StatusBadRequest; (resp.Error != nil) != hasError {
+				t.Fatalf("error = %+v, want error %v", resp.Error, hasError)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	providerType  = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// package paths in the names of generic types, ex: Page[github.com/acme/entity.Task]
+	pkgPathRe = regexp.MustCompile(`[\w.-]+/`)
+)
+
+// schemaGenerator builds the schemas of Go types, named structs are added to the components.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator(schemas map[string]*Schema) *schemaGenerator {
+	return &schemaGenerator{schemas: schemas, names: make(map[reflect.Type]string)}
+}
+
+// schemaOf returns the schema of t, a $ref for named structs.
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Implements(providerType) || reflect.PointerTo(t).Implements(providerType) {
+		return reflect.New(t).Interface().(SchemaProvider).OpenAPISchema()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	// custom encodings, ex: core.UID is a base58 string
+	if t.Kind() == reflect.Struct && (reflect.PointerTo(t).Implements(marshalerType) || reflect.PointerTo(t).Implements(textType)) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, nil)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.componentName(t)}
+	}
+
+	// interfaces and unsupported kinds accept any value
+	return &Schema{}
+}
+
+// componentName registers the schema of a named struct and returns its component name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := typeName(t)
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			break
+		}
+		name = typeName(t) + strconv.Itoa(i)
+	}
+
+	// registered before the fields so recursive types end on a $ref
+	g.names[t] = name
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t, nil)
+
+	return name
+}
+
+func typeName(t reflect.Type) string {
+	name := pkgPathRe.ReplaceAllString(t.Name(), "")
+	return strings.NewReplacer("[", "_", ",", "_", "]", "", "*", "", " ", "").Replace(name)
+}
+
+// structSchema returns the object schema of the fields of t accepted by keep, nil keeps every field.
+func (g *schemaGenerator) structSchema(t reflect.Type, keep func(reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t, keep)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type, keep func(reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a json name are flattened, as encoding/json does
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft, keep)
+				continue
+			}
+		}
+
+		if !f.IsExported() || (keep != nil && !keep(f)) {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schemaOf(f.Type)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			if fs.Ref != "" {
+				fs = &Schema{Ref: fs.Ref}
+			}
+			fs.Description = doc
+		}
+		if applyBindingRules(fs, f.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = fs
+	}
+}
+
+// applyBindingRules adds the validation rules of a binding tag to s, it returns whether the field is required.
+// Rules after dive apply to the items.
+func applyBindingRules(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	target := s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "email":
+			target.Format = "email"
+		case "url", "http_url", "uri":
+			target.Format = "uri"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, enumValue(target, v))
+			}
+		case "len":
+			setBound(target, param, true, true)
+		case "min", "gte":
+			setBound(target, param, true, false)
+		case "max", "lte":
+			setBound(target, param, false, true)
+		case "gt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil {
+				target.ExclusiveMinimum = &f
+			}
+		case "lt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil {
+				target.ExclusiveMaximum = &f
+			}
+		}
+	}
+
+	return required
+}
+
+// setBound sets the length, items or value bounds depending on the schema type.
+func setBound(s *Schema, param string, lower, upper bool) {
+	switch s.Type {
+	case "string", "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if s.Type == "string" {
+			if lower {
+				s.MinLength = &n
+			}
+			if upper {
+				s.MaxLength = &n
+			}
+			return
+		}
+		if lower {
+			s.MinItems = &n
+		}
+		if upper {
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if lower {
+			s.Minimum = &f
+		}
+		if upper {
+			s.Maximum = &f
+		}
+	}
+}
+
+func enumValue(s *Schema, v string) any {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
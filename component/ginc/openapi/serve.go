@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	UISwagger = "swagger"
+	UIRedoc   = "redoc"
+
+	defaultSpecPath = "/openapi.json"
+	defaultUIPath   = "/docs"
+
+	// cdnOrigin serves the UI assets when ServeConfig.Assets is not set.
+	cdnOrigin = "https://cdn.jsdelivr.net"
+)
+
+// ServeConfig configures the routes serving the document.
+type ServeConfig struct {
+	// Path of the document. Default /openapi.json.
+	Path string
+	// UI serves a documentation page, swagger or redoc, empty for none.
+	UI string
+	// UIPath of the documentation page. Default /docs.
+	UIPath string
+	// Assets are the files of the UI, served under UIPath/assets so the page works offline,
+	// ex: the swagger-ui-dist package (swagger-ui.css, swagger-ui-bundle.js) or the redoc bundle
+	// (redoc.standalone.js) embedded with go:embed. Default the jsDelivr CDN.
+	Assets fs.FS
+}
+
+// Serve registers the routes of the document and the documentation page on router, an engine or a group.
+// The document is built when requested, routes registered after Serve are included.
+// The page sends its own Content-Security-Policy allowing the UI assets, it replaces the one of the
+// security headers middleware. It panics on an unknown UI.
+func (a *API) Serve(router gin.IRoutes, cfg ServeConfig) {
+	if cfg.Path == "" {
+		cfg.Path = defaultSpecPath
+	}
+	if cfg.UIPath == "" {
+		cfg.UIPath = defaultUIPath
+	}
+
+	router.GET(cfg.Path, func(c *gin.Context) {
+		b, err := a.MarshalJSON()
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", b)
+	})
+
+	if cfg.UI == "" {
+		return
+	}
+
+	page, ok := uiPages[cfg.UI]
+	if !ok {
+		panic("openapi: unknown UI " + cfg.UI + ", expected swagger or redoc")
+	}
+
+	// the page fetches the document and the assets by their full path, the router may be a group
+	basePath := ""
+	if g, ok := router.(interface{ BasePath() string }); ok {
+		basePath = g.BasePath()
+	}
+
+	assetsURL, assetsSrc := page.cdnURL, cdnOrigin
+	if cfg.Assets != nil {
+		assetsPath := strings.TrimSuffix(cfg.UIPath, "/") + "/assets"
+		router.StaticFS(assetsPath, http.FS(cfg.Assets))
+		assetsURL, assetsSrc = path.Join("/", basePath, assetsPath), "'self'"
+	}
+
+	data := uiData{
+		Title:     a.spec.doc.Info.Title,
+		SpecURL:   path.Join("/", basePath, cfg.Path),
+		AssetsURL: assetsURL,
+	}
+	router.GET(cfg.UIPath, func(c *gin.Context) {
+		data := data
+		data.Nonce = newNonce()
+
+		c.Header("Content-Security-Policy", uiCSP(data.Nonce, assetsSrc))
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := page.tmpl.Execute(c.Writer, data); err != nil {
+			_ = c.Error(err)
+		}
+	})
+}
+
+type uiData struct {
+	Title, SpecURL, AssetsURL, Nonce string
+}
+
+// uiCSP allows the UI assets, the inline script of the page and the styles and workers the UIs create.
+func uiCSP(nonce, assetsSrc string) string {
+	self := "'self'"
+	if assetsSrc != self {
+		self += " " + assetsSrc
+	}
+
+	return "default-src 'none'; script-src 'nonce-" + nonce + "' " + assetsSrc +
+		"; style-src 'unsafe-inline' " + assetsSrc +
+		"; img-src data: " + self +
+		"; font-src data: " + self +
+		"; connect-src 'self'; worker-src blob:; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+type uiPage struct {
+	tmpl *template.Template
+	// cdnURL is the base URL of the assets when ServeConfig.Assets is not set
+	cdnURL string
+}
+
+var uiPages = map[string]uiPage{
+	UISwagger: {cdnURL: cdnOrigin + "/npm/swagger-ui-dist@5", tmpl: template.Must(template.New(UISwagger).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="{{.AssetsURL}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script nonce="{{.Nonce}}" src="{{.AssetsURL}}/swagger-ui-bundle.js"></script>
+  <script nonce="{{.Nonce}}">
+    window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui", validatorUrl: null });
+  </script>
+</body>
+</html>
+`))},
+	UIRedoc: {cdnURL: cdnOrigin + "/npm/redoc@2/bundles", tmpl: template.Must(template.New(UIRedoc).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+</head>
+<body>
+  <redoc spec-url="{{.SpecURL}}"></redoc>
+  <script nonce="{{.Nonce}}" src="{{.AssetsURL}}/redoc.standalone.js"></script>
+</body>
+</html>
+`))},
+}
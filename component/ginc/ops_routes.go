@@ -0,0 +1,162 @@
+package ginc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+)
+
+// Version is the application version reported by the version route.
+// It can be set at build time: -ldflags "-X github.com/taimaifika/service-context/component/ginc.Version=1.0.0"
+// When empty, the main module version from the build info is used.
+var Version = ""
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+)
+
+// registerOpsRoutes mounts the enabled operational routes (liveness, readiness, version, metrics, pprof).
+func (gs *ginEngine) registerOpsRoutes(router gin.IRoutes) {
+	if gs.isLivez {
+		router.GET(gs.livezPath, gs.livezHdl())
+	}
+
+	if gs.isReadyz {
+		router.GET(gs.readyzPath, gs.readyzHdl())
+	}
+
+	if gs.isVersion {
+		router.GET(gs.versionPath, gs.versionHdl())
+	}
+
+	if h := gs.metricsHandler(); h != nil {
+		router.GET(gs.metrics.path, gin.WrapH(h))
+	}
+
+	if gs.isPprof {
+		registerPprofRoutes(router, strings.TrimSuffix(gs.pprofPath, "/"))
+	}
+}
+
+// metricsHandler returns the Prometheus handler of the metrics component, nil when it exposes none.
+// The component must be registered before ginc so it is activated first.
+func (gs *ginEngine) metricsHandler() http.Handler {
+	comp, ok := gs.sv.Get(gs.metrics.component)
+	if !ok {
+		return nil
+	}
+
+	if p, ok := comp.(MetricsHandlerProvider); ok {
+		return p.MetricsHandler()
+	}
+	return nil
+}
+
+// livezHdl reports the process is alive, it never checks dependencies.
+func (gs *ginEngine) livezHdl() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": statusOK})
+	}
+}
+
+// readyzHdl reports whether the service can receive traffic,
+// based on the draining state and the health checks of the service context components.
+// Failed checks are reported as unavailable and logged, their errors may name hosts or DSNs:
+// they are only sent on the admin port or in debug mode.
+func (gs *ginEngine) readyzHdl() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// fail fast while draining, so the load balancer stops routing traffic here
+		if gs.IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": statusDraining})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), gs.readyzTimeout)
+		defer cancel()
+
+		status := statusOK
+		checks := make(map[string]string)
+
+		var results map[string]error
+		if hr, ok := gs.sv.(sctx.HealthReporter); ok {
+			results = hr.HealthCheck(ctx)
+		}
+
+		for id, err := range results {
+			if err != nil {
+				status = statusUnavailable
+				slog.WarnContext(ctx, "readiness check failed", "component", id, "error", err)
+
+				checks[id] = statusUnavailable
+				if gs.adminPort > 0 || mwutil.DebugEnabled() {
+					checks[id] = err.Error()
+				}
+				continue
+			}
+			checks[id] = statusOK
+		}
+
+		code := http.StatusOK
+		if status != statusOK {
+			code = http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, gin.H{"status": status, "checks": checks})
+	}
+}
+
+// versionHdl reports the build info of the running binary.
+func (gs *ginEngine) versionHdl() gin.HandlerFunc {
+	info := gin.H{
+		"service":    gs.name,
+		"env":        gs.sv.EnvName(),
+		"version":    Version,
+		"go_version": runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info["version"] == "" {
+			info["version"] = bi.Main.Version
+		}
+
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info["revision"] = s.Value
+			case "vcs.time":
+				info["build_time"] = s.Value
+			case "vcs.modified":
+				info["modified"] = s.Value == "true"
+			}
+		}
+	}
+
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, info)
+	}
+}
+
+// registerPprofRoutes mounts net/http/pprof handlers under the prefix.
+func registerPprofRoutes(router gin.IRoutes, prefix string) {
+	router.GET(prefix+"/", gin.WrapF(pprof.Index))
+	router.GET(prefix+"/cmdline", gin.WrapF(pprof.Cmdline))
+	router.GET(prefix+"/profile", gin.WrapF(pprof.Profile))
+	router.GET(prefix+"/symbol", gin.WrapF(pprof.Symbol))
+	router.POST(prefix+"/symbol", gin.WrapF(pprof.Symbol))
+	router.GET(prefix+"/trace", gin.WrapF(pprof.Trace))
+
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		router.GET(prefix+"/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
@@ -0,0 +1,141 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	sctx "github.com/taimaifika/service-context"
+)
+
+// RouteRegistrar is implemented by components or service modules owning routes.
+// Components implementing it are discovered when the server starts, after Load,
+// other modules are added with AddRoutes.
+type RouteRegistrar interface {
+	RegisterRoutes(group *gin.RouterGroup)
+}
+
+// RouteFunc adapts a function to RouteRegistrar.
+type RouteFunc func(group *gin.RouterGroup)
+
+func (f RouteFunc) RegisterRoutes(group *gin.RouterGroup) {
+	f(group)
+}
+
+// RouteOptions configures the group given to a RouteRegistrar.
+type RouteOptions struct {
+	// Version groups the routes under an API version, ex: v1 mounts them under /v1.
+	Version string
+	// Prefix of the routes in the version group, ex: /tasks.
+	Prefix string
+	// Middlewares run before the handlers of the module only, ex: authentication.
+	Middlewares []gin.HandlerFunc
+}
+
+// RouteOptionsProvider can be implemented by a RouteRegistrar to set its options.
+type RouteOptionsProvider interface {
+	RouteOptions() RouteOptions
+}
+
+// Module wraps a registrar with options, ex: for modules that are not components
+// or to mount the same module under several versions.
+func Module(registrar RouteRegistrar, opts RouteOptions) RouteRegistrar {
+	return &module{RouteRegistrar: registrar, opts: opts}
+}
+
+type module struct {
+	RouteRegistrar
+	opts RouteOptions
+}
+
+func (m *module) RouteOptions() RouteOptions {
+	return m.opts
+}
+
+// AddRoutes adds modules registered when the server starts, after the discovered components,
+// in the order they are added. It has no effect once the server is started.
+func (gs *ginEngine) AddRoutes(registrars ...RouteRegistrar) {
+	gs.registrars = append(gs.registrars, registrars...)
+}
+
+// routesConfig holds the route registration flags.
+type routesConfig struct {
+	basePath string
+}
+
+func (rc *routesConfig) initFlags(prefix string) {
+	flag.StringVar(&rc.basePath, prefix+"-routes-base-path", "", "path prefix of the routes registered by modules (RouteRegistrar), ex: /api mounts version v1 under /api/v1. Default none")
+}
+
+// registerRoutes mounts the routes of the components implementing RouteRegistrar, then the added modules.
+// Groups are nested as base path, version, then module prefix. A route registered twice is returned as an error.
+func (gs *ginEngine) registerRoutes() error {
+	if gs.routesRegistered {
+		return nil
+	}
+	gs.routesRegistered = true
+
+	type namedRegistrar struct {
+		name string
+		RouteRegistrar
+	}
+
+	var registrars []namedRegistrar
+	if lister, ok := gs.sv.(sctx.ComponentLister); ok {
+		for _, c := range lister.Components() {
+			if r, ok := c.(RouteRegistrar); ok {
+				registrars = append(registrars, namedRegistrar{name: c.ID(), RouteRegistrar: r})
+			}
+		}
+	}
+	for _, r := range gs.registrars {
+		name := fmt.Sprintf("%T", r)
+		if m, ok := r.(*module); ok {
+			name = fmt.Sprintf("%T", m.RouteRegistrar)
+		}
+		registrars = append(registrars, namedRegistrar{name: name, RouteRegistrar: r})
+	}
+
+	base := gs.router.Group(gs.routes.basePath)
+	versions := make(map[string]*gin.RouterGroup)
+
+	for _, r := range registrars {
+		var opts RouteOptions
+		if p, ok := r.RouteRegistrar.(RouteOptionsProvider); ok {
+			opts = p.RouteOptions()
+		}
+
+		parent := base
+		if opts.Version != "" {
+			version := strings.Trim(opts.Version, "/")
+			if versions[version] == nil {
+				versions[version] = base.Group("/" + version)
+			}
+			parent = versions[version]
+		}
+
+		group := parent.Group(opts.Prefix, opts.Middlewares...)
+		if err := registerModule(r, group); err != nil {
+			return fmt.Errorf("register routes of %s: %w", r.name, err)
+		}
+
+		slog.Info("routes registered", "module", r.name, "path", group.BasePath())
+	}
+
+	return nil
+}
+
+// registerModule returns the panic of gin on invalid or conflicting routes as an error.
+func registerModule(r RouteRegistrar, group *gin.RouterGroup) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+
+	r.RegisterRoutes(group)
+	return nil
+}
@@ -0,0 +1,52 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+// securityHeadersConfig holds the security headers flags, see middleware.SecurityHeadersConfig.
+type securityHeadersConfig struct {
+	preset        string
+	csp           string
+	cspReportOnly bool
+	hstsMaxAge    time.Duration
+	hstsPreload   bool
+}
+
+func (sc *securityHeadersConfig) initFlags(prefix string) {
+	flag.StringVar(&sc.preset, prefix+"-security-headers", "", "security headers preset (api | web), empty to disable. Default disabled")
+	flag.StringVar(&sc.csp, prefix+"-security-csp", "", "Content-Security-Policy overriding the preset one, {nonce} is replaced by a per-request nonce")
+	flag.BoolVar(&sc.cspReportOnly, prefix+"-security-csp-report-only", false, "send the policy as Content-Security-Policy-Report-Only. Default false")
+	flag.DurationVar(&sc.hstsMaxAge, prefix+"-security-hsts-max-age", 365*24*time.Hour, "Strict-Transport-Security max-age, 0 to disable. Default 8760h")
+	flag.BoolVar(&sc.hstsPreload, prefix+"-security-hsts-preload", false, "add preload to Strict-Transport-Security. Default false")
+}
+
+func (sc *securityHeadersConfig) isEnabled() bool {
+	return sc.preset != ""
+}
+
+// middleware builds the security headers middleware from flags.
+func (sc *securityHeadersConfig) middleware() (gin.HandlerFunc, error) {
+	cfg, err := middleware.SecurityHeadersPreset(sc.preset)
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.csp != "" {
+		cfg.ContentSecurityPolicy = sc.csp
+	}
+	cfg.CSPReportOnly = sc.cspReportOnly
+	cfg.HSTSMaxAge = sc.hstsMaxAge
+	cfg.HSTSPreload = sc.hstsPreload
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid security headers: %w", err)
+	}
+
+	return middleware.SecurityHeadersWithConfig(cfg), nil
+}
@@ -0,0 +1,184 @@
+package ginc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/taimaifika/service-context/component/internal/certreload"
+)
+
+// Start registers the routes of the modules, builds the http.Server around the router and starts serving in the background.
+// When an admin port is configured, the operational routes are served on a second server.
+// Listeners are opened synchronously, so errors like "address already in use" are returned.
+func (gs *ginEngine) Start() error {
+	if gs.server != nil {
+		return errors.New("gin server already started")
+	}
+
+	if err := gs.registerRoutes(); err != nil {
+		return err
+	}
+
+	srv, err := gs.newServer(gs.port, gs.router)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	if gs.adminRouter != nil {
+		adminSrv, err := gs.newServer(gs.adminPort, gs.adminRouter)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+
+		adminLn, err := net.Listen("tcp", adminSrv.Addr)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+
+		gs.adminServer = adminSrv
+		go gs.serve(adminSrv, adminLn)
+
+		slog.Info("gin admin server started", "id", gs.id, "port", gs.adminPort)
+	}
+
+	srv.RegisterOnShutdown(gs.notifyShutdown)
+
+	gs.server = srv
+	go gs.serve(srv, ln)
+
+	slog.Info("gin server started", "id", gs.id, "port", gs.port, "tls", srv.TLSConfig != nil, "h2c", gs.isH2C && srv.TLSConfig == nil)
+
+	return nil
+}
+
+func (gs *ginEngine) serve(srv *http.Server, ln net.Listener) {
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("gin server error", "id", gs.id, "addr", srv.Addr, "error", err)
+	}
+}
+
+// newServer creates the http.Server with timeouts, TLS and protocols from config.
+func (gs *ginEngine) newServer(port int, handler http.Handler) (*http.Server, error) {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadTimeout:       gs.readTimeout,
+		ReadHeaderTimeout: gs.readHeaderTimeout,
+		WriteTimeout:      gs.writeTimeout,
+		IdleTimeout:       gs.idleTimeout,
+		MaxHeaderBytes:    gs.maxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+
+	if gs.isTLSEnabled() {
+		reloader, err := certreload.New(gs.tlsCertFile, gs.tlsKeyFile, gs.tlsReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	} else if gs.isH2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		srv.Protocols = protocols
+	}
+
+	return srv, nil
+}
+
+// drain fails readiness and keeps serving for the drain delay, so the load balancer
+// removes the instance before the server stops accepting connections.
+func (gs *ginEngine) drain() {
+	if gs.server == nil || gs.draining.Swap(true) {
+		return
+	}
+
+	// ask clients to reconnect elsewhere instead of reusing connections to this instance
+	gs.server.SetKeepAlivesEnabled(false)
+
+	if gs.drainDelay > 0 {
+		slog.Info("draining gin server...", "id", gs.id, "delay", gs.drainDelay)
+		time.Sleep(gs.drainDelay)
+	}
+}
+
+// notifyShutdown closes the shutdown channel once, it is called when Shutdown starts.
+func (gs *ginEngine) notifyShutdown() {
+	gs.shutdownOnce.Do(func() {
+		close(gs.shutdownCh)
+		gs.streams.Close()
+	})
+}
+
+// shutdown stops accepting new connections and waits for in-flight requests.
+// Connections still open after the shutdown timeout are closed forcibly.
+func (gs *ginEngine) shutdown() error {
+	if gs.server == nil {
+		return nil
+	}
+
+	slog.Info("shutting down gin server...", "id", gs.id, "timeout", gs.shutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gs.shutdownTimeout)
+	defer cancel()
+
+	err := shutdownServer(ctx, gs.server)
+
+	// hijacked WebSocket connections are not waited for by the server,
+	// the streams are closed by then as Shutdown notifies in a goroutine
+	gs.notifyShutdown()
+	if werr := gs.streams.Wait(ctx); werr != nil {
+		err = errors.Join(err, fmt.Errorf("%d streams still open: %w", gs.streams.Len(), werr))
+	}
+
+	// the admin server goes last, so probes keep answering while the main server drains
+	if gs.adminServer != nil {
+		err = errors.Join(err, shutdownServer(ctx, gs.adminServer))
+	}
+
+	if err != nil {
+		slog.Error("gin server shutdown error", "id", gs.id, "error", err)
+		return err
+	}
+
+	slog.Info("gin server stopped", "id", gs.id)
+
+	return nil
+}
+
+// shutdownServer gracefully shuts down srv, closing remaining connections when ctx expires.
+func shutdownServer(ctx context.Context, srv *http.Server) error {
+	if err := srv.Shutdown(ctx); err != nil {
+		return errors.Join(err, srv.Close())
+	}
+
+	return nil
+}
+
+func (gs *ginEngine) isTLSEnabled() bool {
+	return gs.tlsCertFile != "" && gs.tlsKeyFile != ""
+}
@@ -0,0 +1,232 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+const defaultHeartbeat = 15 * time.Second
+
+// ErrShuttingDown is answered with 503 to streams opened after the tracker is closed.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// SSEConfig configures the Server-Sent Events handler.
+type SSEConfig struct {
+	// Tracker, when set, tracks the stream so it ends on shutdown.
+	Tracker *Tracker
+	// Heartbeat is the interval of the comment lines keeping idle connections open
+	// through proxies. Default 15s, negative to disable.
+	Heartbeat time.Duration
+	// Retry is the reconnection delay advised to the browser, 0 keeps the browser default.
+	Retry time.Duration
+}
+
+// Event is a Server-Sent Event.
+type Event struct {
+	// ID is sent back by the browser in Last-Event-ID when it reconnects.
+	ID string
+	// Event is the event name, empty for the default "message" event.
+	Event string
+	// Data is sent as is when it is a string or []byte, as JSON otherwise.
+	Data any
+	// Retry overrides the reconnection delay advised to the browser, 0 to keep it.
+	Retry time.Duration
+}
+
+// SSEStream writes events to a client, it is safe for concurrent use.
+type SSEStream struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	w           gin.ResponseWriter
+	lastEventID string
+
+	mu sync.Mutex
+}
+
+// Context is done when the client disconnects or the tracker is closed.
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventID returns the ID of the last event received by the client before it reconnected,
+// read from the Last-Event-ID header or the lastEventId query parameter, empty on the first connection.
+// Send the events following it to resume the stream.
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Send writes and flushes an event. It returns the context error once the stream has ended.
+func (s *SSEStream) Send(ev Event) error {
+	var b strings.Builder
+
+	if ev.ID != "" {
+		b.WriteString("id: " + singleLine(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		b.WriteString("event: " + singleLine(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	data, err := eventData(ev.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+func (s *SSEStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.WriteString(msg); err != nil {
+		s.cancel()
+		return err
+	}
+	s.w.Flush()
+
+	return nil
+}
+
+// SSE streams events with the default configuration, see SSEWithConfig.
+func SSE(handler func(c *gin.Context, s *SSEStream) error) gin.HandlerFunc {
+	return SSEWithConfig(SSEConfig{}, handler)
+}
+
+// SSEWithConfig answers with a text/event-stream and runs handler, the stream ends when it returns.
+// Handlers send events until s.Context() is done, ex: on client disconnect or shutdown.
+// The error returned by handler is added to the gin context errors, the response has started already.
+// The route must have no request timeout, the write timeout of the server is lifted for the stream.
+func SSEWithConfig(cfg SSEConfig, handler func(c *gin.Context, s *SSEStream) error) gin.HandlerFunc {
+	if cfg.Heartbeat == 0 {
+		cfg.Heartbeat = defaultHeartbeat
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		release, ok := cfg.Tracker.add(cancel)
+		if !ok {
+			writeShuttingDown(c)
+			return
+		}
+		defer release()
+
+		s := &SSEStream{
+			ctx:         ctx,
+			cancel:      cancel,
+			w:           c.Writer,
+			lastEventID: lastEventID(c),
+		}
+
+		// the server write timeout would end the stream, errors mean the writer does not support it
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+		h := c.Writer.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		// disables the response buffering of nginx
+		h.Set("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		open := ": connected\n\n"
+		if cfg.Retry > 0 {
+			open = "retry: " + strconv.FormatInt(cfg.Retry.Milliseconds(), 10) + "\n\n"
+		}
+		if err := s.write(open); err != nil {
+			return
+		}
+
+		var wg sync.WaitGroup
+		if cfg.Heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				heartbeat(s, cfg.Heartbeat)
+			}()
+		}
+
+		if err := handler(c, s); err != nil && !errors.Is(err, context.Canceled) {
+			_ = c.Error(err)
+		}
+
+		cancel()
+		wg.Wait()
+	}
+}
+
+// heartbeat writes a comment line every interval until the stream ends.
+func heartbeat(s *SSEStream, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": ping\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func lastEventID(c *gin.Context) string {
+	id := c.GetHeader("Last-Event-ID")
+	if id == "" {
+		id = c.Query("lastEventId")
+	}
+	return singleLine(id)
+}
+
+// singleLine drops line breaks, they would end the field.
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func eventData(data any) (string, error) {
+	switch d := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func writeShuttingDown(c *gin.Context) {
+	c.Header("Retry-After", "1")
+	core.WriteStandardErrorResponse(c, http.StatusServiceUnavailable,
+		mwutil.ErrorContext().ServiceUnavailableError(ErrShuttingDown.Error(), "the server stops accepting streams"))
+	c.Abort()
+}
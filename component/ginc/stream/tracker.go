@@ -0,0 +1,97 @@
+// Package stream provides Server-Sent Events and WebSocket handlers for gin,
+// with the open connections tracked so they are closed on graceful shutdown.
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracker tracks the open streams. Close ends them, ex: when the server shuts down,
+// and Wait waits for them to finish their close handshake.
+// ginc creates one closed with the server, see its Streams method.
+type Tracker struct {
+	mu      sync.Mutex
+	closers map[uint64]func()
+	next    uint64
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{closers: make(map[uint64]func())}
+}
+
+// add registers the close function of a stream, it returns false once the tracker is closed.
+// The returned release function must be called when the stream ends.
+func (t *Tracker) add(closeFn func()) (release func(), ok bool) {
+	if t == nil {
+		return func() {}, true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, false
+	}
+
+	id := t.next
+	t.next++
+	t.closers[id] = closeFn
+	t.wg.Add(1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			delete(t.closers, id)
+			t.mu.Unlock()
+			t.wg.Done()
+		})
+	}, true
+}
+
+// Len returns the number of open streams.
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.closers)
+}
+
+// Close asks every open stream to end and rejects new ones, it does not wait for them.
+func (t *Tracker) Close() {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+
+	closers := make([]func(), 0, len(t.closers))
+	for _, fn := range t.closers {
+		closers = append(closers, fn)
+	}
+	t.mu.Unlock()
+
+	for _, fn := range closers {
+		fn()
+	}
+}
+
+// Wait waits for the open streams to end, it returns the context error when ctx is done first.
+// Call it after Close, streams opened while waiting are not waited for.
+func (t *Tracker) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
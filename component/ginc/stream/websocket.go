@@ -0,0 +1,251 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+const (
+	defaultMaxMessageSize = 32 << 10
+	defaultPingInterval   = 30 * time.Second
+	defaultWriteTimeout   = 10 * time.Second
+)
+
+// Message types and close codes, see RFC 6455.
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+
+	CloseNormalClosure   = websocket.CloseNormalClosure
+	CloseGoingAway       = websocket.CloseGoingAway
+	ClosePolicyViolation = websocket.ClosePolicyViolation
+	CloseInternalErr     = websocket.CloseInternalServerErr
+)
+
+// WebSocketConfig configures the WebSocket handler.
+type WebSocketConfig struct {
+	// Tracker, when set, tracks the connection so it is closed with 1001 (going away) on shutdown.
+	// Connections opened once the tracker is closed are closed right after the upgrade.
+	Tracker *Tracker
+	// RequireRequester rejects requests without a core.Requester with 401 before the upgrade,
+	// the auth middleware must run before the handler.
+	RequireRequester bool
+	// MaxMessageSize is the size limit of received messages in bytes, larger messages
+	// close the connection with 1009. Default 32KiB.
+	MaxMessageSize int64
+	// PingInterval is the interval of the pings, the connection is closed when no pong is
+	// received within two intervals. Default 30s.
+	PingInterval time.Duration
+	// WriteTimeout is the deadline of a write. Default 10s.
+	WriteTimeout time.Duration
+	// CheckOrigin accepts the Origin of the request. Default same host only,
+	// as browsers do not apply CORS to WebSocket.
+	CheckOrigin func(r *http.Request) bool
+	// Subprotocols supported by the server in order of preference.
+	Subprotocols []string
+	// OnMessage is called for each received message, in order. A returned error closes the
+	// connection with 1011. Default messages are discarded.
+	OnMessage func(conn *WebSocketConn, messageType int, data []byte) error
+}
+
+// WebSocketConn is an upgraded connection, its write methods are safe for concurrent use.
+type WebSocketConn struct {
+	ws           *websocket.Conn
+	ctx          context.Context
+	cancel       context.CancelFunc
+	requester    core.Requester
+	writeTimeout time.Duration
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+// Context is done when the connection is closed by the client, the server or on shutdown.
+// It carries the values of the request context, ex: core.GetRequestID.
+func (conn *WebSocketConn) Context() context.Context {
+	return conn.ctx
+}
+
+// Requester returns the authenticated requester of the upgrade request, nil for anonymous connections.
+func (conn *WebSocketConn) Requester() core.Requester {
+	return conn.requester
+}
+
+// Subprotocol returns the negotiated subprotocol, empty when none.
+func (conn *WebSocketConn) Subprotocol() string {
+	return conn.ws.Subprotocol()
+}
+
+// WriteMessage sends a text or binary message.
+func (conn *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	if err := conn.ctx.Err(); err != nil {
+		return err
+	}
+
+	_ = conn.ws.SetWriteDeadline(time.Now().Add(conn.writeTimeout))
+	if err := conn.ws.WriteMessage(messageType, data); err != nil {
+		conn.cancel()
+		return err
+	}
+	return nil
+}
+
+// WriteJSON sends v as a JSON text message.
+func (conn *WebSocketConn) WriteJSON(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(TextMessage, b)
+}
+
+// Close starts the close handshake with a status code, ex: CloseNormalClosure, and ends the context.
+// Only the first call sends the close frame.
+func (conn *WebSocketConn) Close(code int, reason string) {
+	conn.closeOnce.Do(func() {
+		msg := websocket.FormatCloseMessage(code, reason)
+		_ = conn.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(conn.writeTimeout))
+		conn.cancel()
+	})
+}
+
+// WebSocket upgrades the connection with the default configuration, see WebSocketWithConfig.
+func WebSocket(handler func(c *gin.Context, conn *WebSocketConn) error) gin.HandlerFunc {
+	return WebSocketWithConfig(WebSocketConfig{}, handler)
+}
+
+// WebSocketWithConfig upgrades the connection and runs handler, the connection is closed when it returns.
+// Received messages are read in the background and passed to cfg.OnMessage, so pongs and close frames
+// are handled while handler writes. Handlers should return once conn.Context() is done.
+// A nil error closes the connection with 1000, an error with 1011 and is added to the gin context errors.
+// The route must have no request timeout, timeout and cache middlewares do not support the upgrade.
+func WebSocketWithConfig(cfg WebSocketConfig, handler func(c *gin.Context, conn *WebSocketConn) error) gin.HandlerFunc {
+	if cfg.MaxMessageSize <= 0 {
+		cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  cfg.CheckOrigin,
+		Subprotocols: cfg.Subprotocols,
+	}
+
+	return func(c *gin.Context) {
+		requester := core.GetRequester(c.Request.Context())
+		if cfg.RequireRequester && requester == nil {
+			core.WriteStandardErrorResponse(c, http.StatusUnauthorized,
+				mwutil.ErrorContext().UnauthorizedError(core.ErrUnauthorized.Error(), "authentication is required to open a WebSocket"))
+			c.Abort()
+			return
+		}
+
+		// the upgrader answers the failed handshakes itself
+		ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		defer ws.Close()
+
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		conn := &WebSocketConn{
+			ws:           ws,
+			ctx:          ctx,
+			cancel:       cancel,
+			requester:    requester,
+			writeTimeout: cfg.WriteTimeout,
+		}
+
+		goingAway := func() { conn.Close(CloseGoingAway, ErrShuttingDown.Error()) }
+		release, ok := cfg.Tracker.add(goingAway)
+		if !ok {
+			goingAway()
+			return
+		}
+		defer release()
+
+		pongWait := 2 * cfg.PingInterval
+		ws.SetReadLimit(cfg.MaxMessageSize)
+		_ = ws.SetReadDeadline(time.Now().Add(pongWait))
+		ws.SetPongHandler(func(string) error {
+			return ws.SetReadDeadline(time.Now().Add(pongWait))
+		})
+
+		readDone := make(chan struct{})
+		go func() {
+			defer close(readDone)
+			readMessages(conn, cfg.OnMessage)
+		}()
+		go pingLoop(conn, cfg.PingInterval)
+
+		err = handler(c, conn)
+		switch {
+		case err != nil && !errors.Is(err, context.Canceled):
+			_ = c.Error(err)
+			conn.Close(CloseInternalErr, "internal error")
+		default:
+			conn.Close(CloseNormalClosure, "")
+		}
+
+		// waits for the close frame of the client, bounded by the write timeout
+		_ = ws.SetReadDeadline(time.Now().Add(cfg.WriteTimeout))
+		<-readDone
+	}
+}
+
+// readMessages reads until the connection fails or is closed, then ends the context.
+func readMessages(conn *WebSocketConn, onMessage func(conn *WebSocketConn, messageType int, data []byte) error) {
+	defer conn.cancel()
+
+	for {
+		messageType, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if onMessage == nil || conn.ctx.Err() != nil {
+			continue
+		}
+		if err := onMessage(conn, messageType, data); err != nil {
+			conn.Close(CloseInternalErr, "internal error")
+		}
+	}
+}
+
+// pingLoop sends a ping every interval until the connection ends.
+func pingLoop(conn *WebSocketConn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(conn.writeTimeout)); err != nil {
+				conn.cancel()
+				return
+			}
+		}
+	}
+}
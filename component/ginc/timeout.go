@@ -0,0 +1,55 @@
+package ginc
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+// timeoutConfig holds the request timeout flags, see middleware.TimeoutConfig.
+type timeoutConfig struct {
+	timeout    time.Duration
+	routes     string
+	statusCode int
+}
+
+func (tc *timeoutConfig) initFlags(prefix string) {
+	flag.DurationVar(&tc.timeout, prefix+"-request-timeout", 0, "deadline of every request, 0 to disable. Default 0")
+	flag.StringVar(&tc.routes, prefix+"-request-timeout-routes", "", "comma-separated timeouts per route prefix, ex: /v1/reports=30s,POST /v1/uploads=2m,/v1/events=0")
+	flag.IntVar(&tc.statusCode, prefix+"-request-timeout-status", 504, "status answered when the request deadline passes, 503 or 504. Default 504")
+}
+
+func (tc *timeoutConfig) isEnabled() bool {
+	return tc.timeout > 0 || tc.routes != ""
+}
+
+// middleware builds the timeout middleware from flags.
+func (tc *timeoutConfig) middleware() (gin.HandlerFunc, error) {
+	if tc.statusCode != 503 && tc.statusCode != 504 {
+		return nil, fmt.Errorf("request timeout status must be 503 or 504, got %d", tc.statusCode)
+	}
+
+	routes := make(map[string]time.Duration)
+	for _, item := range splitList(tc.routes) {
+		route, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid request timeout route %q, expected route=duration", item)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid request timeout route %q: %w", item, err)
+		}
+		routes[strings.TrimSpace(route)] = d
+	}
+
+	return middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Timeout:    tc.timeout,
+		Routes:     routes,
+		StatusCode: tc.statusCode,
+	}), nil
+}
@@ -0,0 +1,31 @@
+package ginc
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+)
+
+// tracingConfig holds the tracing flags, see middleware.TracingConfig.
+type tracingConfig struct {
+	isEnabled      bool
+	excludePaths   string
+	excludeMethods string
+}
+
+func (tc *tracingConfig) initFlags(prefix string) {
+	flag.BoolVar(&tc.isEnabled, prefix+"-tracing-enabled", false, "trace requests with otelgin and the global tracer provider, named after the service context. Default false")
+	flag.StringVar(&tc.excludePaths, prefix+"-tracing-exclude-paths", "", "comma separated paths or route templates not traced, a trailing * matches a prefix (e.g. /ping,/static/*). The operational routes are never traced")
+	flag.StringVar(&tc.excludeMethods, prefix+"-tracing-exclude-methods", "", "comma separated methods not traced (e.g. OPTIONS,HEAD)")
+}
+
+// middleware builds the tracing middleware, opsPaths are excluded along with the flag paths.
+func (tc *tracingConfig) middleware(serviceName string, opsPaths []string) gin.HandlerFunc {
+	return middleware.TracingWithConfig(middleware.TracingConfig{
+		ServiceName:    serviceName,
+		ExcludePaths:   append(opsPaths, splitList(tc.excludePaths)...),
+		ExcludeMethods: splitList(strings.ToUpper(tc.excludeMethods)),
+	})
+}
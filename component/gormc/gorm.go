@@ -1,6 +1,7 @@
 package gormc
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -161,6 +162,16 @@ func (gdb *gormDB) Stop() error {
 	return nil
 }
 
+// HealthCheck pings the database, it implements sctx.HealthChecker.
+func (gdb *gormDB) HealthCheck(ctx context.Context) error {
+	db, err := gdb.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.PingContext(ctx)
+}
+
 func (gdb *gormDB) GetDB() *gorm.DB {
 	var newSessionDB *gorm.DB
 	if gdb.logLevel == "debug" || gdb.logLevel == "trace" {
@@ -0,0 +1,239 @@
+// Package grpcc is a gRPC server component with OTel instrumentation, logging, recovery and error
+// mapping interceptors, the grpc.health.v1 service backed by the components health checks and reflection.
+package grpcc
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+
+	sctx "github.com/taimaifika/service-context"
+)
+
+const (
+	defaultPort = 50051
+
+	defaultMaxRecvMsgSize    = 4 << 20 // 4MiB, the grpc default
+	defaultMaxSendMsgSize    = math.MaxInt32
+	defaultShutdownTimeout   = 15 * time.Second
+	defaultTLSReloadInterval = time.Minute
+
+	defaultKeepaliveTime    = 2 * time.Hour
+	defaultKeepaliveTimeout = 20 * time.Second
+	defaultKeepaliveMinTime = 5 * time.Minute
+
+	defaultHealthInterval = 10 * time.Second
+	defaultHealthTimeout  = 3 * time.Second
+)
+
+type Config struct {
+	port int
+
+	// message limits
+	maxRecvMsgSize       int
+	maxSendMsgSize       int
+	maxConcurrentStreams uint
+
+	// keepalive
+	keepaliveTime                time.Duration
+	keepaliveTimeout             time.Duration
+	keepaliveMinTime             time.Duration
+	keepalivePermitWithoutStream bool
+	maxConnectionIdle            time.Duration
+	maxConnectionAge             time.Duration
+	maxConnectionAgeGrace        time.Duration
+
+	// graceful shutdown deadline
+	shutdownTimeout time.Duration
+	// time to keep serving after health starts failing, before shutdown starts
+	drainDelay time.Duration
+
+	// TLS
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsReloadInterval time.Duration
+
+	// Health and reflection
+	isHealth       bool
+	healthInterval time.Duration
+	healthTimeout  time.Duration
+	isReflection   bool
+
+	// Interceptors
+	isTracing             bool
+	tracingExcludeMethods string
+	isLogging             bool
+	loggingSkipMethods    string
+}
+
+type grpcServer struct {
+	*Config
+	id   string
+	name string
+	sv   sctx.ServiceContext
+
+	services           []service
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	serverOptions      []grpc.ServerOption
+
+	server *grpc.Server
+	health *health.Server
+
+	// draining is set when Stop is called, health checks fail from then on
+	draining atomic.Bool
+	// shutdownCh is closed when the server stops accepting new RPCs
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// service is a service registered before Start.
+type service struct {
+	desc *grpc.ServiceDesc
+	impl any
+}
+
+func NewGRPC(id string) *grpcServer {
+	return &grpcServer{
+		Config:     new(Config),
+		id:         id,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+func (s *grpcServer) ID() string {
+	return s.id
+}
+
+func (s *grpcServer) InitFlags() {
+	flag.IntVar(&s.Config.port, s.id+"-port", defaultPort, "grpc server port. Default 50051")
+
+	// Message limits
+	flag.IntVar(&s.Config.maxRecvMsgSize, s.id+"-max-recv-msg-size", defaultMaxRecvMsgSize, "maximum size in bytes of a received message. Default 4194304")
+	flag.IntVar(&s.Config.maxSendMsgSize, s.id+"-max-send-msg-size", defaultMaxSendMsgSize, "maximum size in bytes of a sent message. Default 2147483647")
+	flag.UintVar(&s.Config.maxConcurrentStreams, s.id+"-max-concurrent-streams", 0, "maximum number of concurrent streams per connection, 0 is unlimited. Default 0")
+
+	// Keepalive
+	flag.DurationVar(&s.Config.keepaliveTime, s.id+"-keepalive-time", defaultKeepaliveTime, "ping an idle client connection after this duration. Default 2h")
+	flag.DurationVar(&s.Config.keepaliveTimeout, s.id+"-keepalive-timeout", defaultKeepaliveTimeout, "close the connection when a ping is not answered within this duration. Default 20s")
+	flag.DurationVar(&s.Config.keepaliveMinTime, s.id+"-keepalive-min-time", defaultKeepaliveMinTime, "minimum interval between client pings, faster clients are disconnected. Default 5m")
+	flag.BoolVar(&s.Config.keepalivePermitWithoutStream, s.id+"-keepalive-permit-without-stream", false, "allow client pings when there is no active stream. Default false")
+	flag.DurationVar(&s.Config.maxConnectionIdle, s.id+"-max-connection-idle", 0, "close connections idle for this duration, 0 is infinity. Default 0s")
+	flag.DurationVar(&s.Config.maxConnectionAge, s.id+"-max-connection-age", 0, "close connections older than this duration so clients rebalance, 0 is infinity. Default 0s")
+	flag.DurationVar(&s.Config.maxConnectionAgeGrace, s.id+"-max-connection-age-grace", 0, "time given to pending RPCs after max connection age, 0 is infinity. Default 0s")
+
+	flag.DurationVar(&s.Config.shutdownTimeout, s.id+"-shutdown-timeout", defaultShutdownTimeout, "maximum duration to wait for in-flight RPCs on shutdown. Default 15s")
+	flag.DurationVar(&s.Config.drainDelay, s.id+"-drain-delay", 0, "time to keep serving after health starts failing on stop, should cover the load balancer deregistration. Default 0s")
+
+	// TLS
+	flag.StringVar(&s.Config.tlsCertFile, s.id+"-tls-cert-file", "", "path to the TLS certificate file, TLS is enabled when both cert and key are set")
+	flag.StringVar(&s.Config.tlsKeyFile, s.id+"-tls-key-file", "", "path to the TLS private key file")
+	flag.DurationVar(&s.Config.tlsReloadInterval, s.id+"-tls-reload-interval", defaultTLSReloadInterval, "how often the TLS cert/key files are checked for changes. Default 1m")
+
+	// Health and reflection
+	flag.BoolVar(&s.Config.isHealth, s.id+"-health-enabled", true, "serve grpc.health.v1 backed by components health checks. Default true")
+	flag.DurationVar(&s.Config.healthInterval, s.id+"-health-interval", defaultHealthInterval, "how often the components health checks are run. Default 10s")
+	flag.DurationVar(&s.Config.healthTimeout, s.id+"-health-timeout", defaultHealthTimeout, "timeout of components health checks. Default 3s")
+	flag.BoolVar(&s.Config.isReflection, s.id+"-reflection-enabled", false, "serve the reflection service for tools like grpcurl. Default false")
+
+	// Interceptors
+	flag.BoolVar(&s.Config.isTracing, s.id+"-tracing-enabled", false, "trace and measure RPCs with otelgrpc and the global providers. Default false")
+	flag.StringVar(&s.Config.tracingExcludeMethods, s.id+"-tracing-exclude-methods", "", "comma separated full methods not traced, a trailing * matches a prefix (e.g. /pkg.Service/*). The health service is never traced")
+	flag.BoolVar(&s.Config.isLogging, s.id+"-logging-enabled", true, "log one record per RPC. Default true")
+	flag.StringVar(&s.Config.loggingSkipMethods, s.id+"-logging-skip-methods", "", "comma separated full methods not logged, a trailing * matches a prefix. The health service is never logged")
+}
+
+func (s *grpcServer) Activate(sv sctx.ServiceContext) error {
+	s.name = sv.GetName()
+	s.sv = sv
+
+	if (s.tlsCertFile == "") != (s.tlsKeyFile == "") {
+		return fmt.Errorf("-%s-tls-cert-file and -%s-tls-key-file must be set together", s.id, s.id)
+	}
+
+	if s.isHealth {
+		if s.healthInterval <= 0 {
+			return fmt.Errorf("grpc health interval must be positive, got %s", s.healthInterval)
+		}
+		s.health = health.NewServer()
+	}
+
+	return nil
+}
+
+// Stop drains the server for rolling updates: health fails first, the server keeps serving during
+// the drain delay, then it stops accepting new RPCs and waits for in-flight ones within the shutdown timeout.
+// Register the server after the components its services use, so they are closed once it is drained.
+func (s *grpcServer) Stop() error {
+	s.drain()
+	return s.shutdown()
+}
+
+func (s *grpcServer) GetPort() int {
+	return s.port
+}
+
+// RegisterService registers a service implementation, it makes the component a grpc.ServiceRegistrar
+// for the generated functions, ex: pb.RegisterTaskServiceServer(comp, impl).
+// Services are added to the server on Start, registering after Start panics.
+func (s *grpcServer) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	if s.server != nil {
+		panic("grpc: RegisterService after Start")
+	}
+	s.services = append(s.services, service{desc: desc, impl: impl})
+}
+
+// UseUnary adds unary interceptors, ex: interceptor.UnaryAuth. They run in order after the built-in
+// request ID, logging, recovery and error interceptors. Interceptors added after Start have no effect.
+func (s *grpcServer) UseUnary(interceptors ...grpc.UnaryServerInterceptor) {
+	s.unaryInterceptors = append(s.unaryInterceptors, interceptors...)
+}
+
+// UseStream adds stream interceptors, see UseUnary.
+func (s *grpcServer) UseStream(interceptors ...grpc.StreamServerInterceptor) {
+	s.streamInterceptors = append(s.streamInterceptors, interceptors...)
+}
+
+// AddServerOptions adds options to the grpc.Server created on Start, ex: a custom codec.
+func (s *grpcServer) AddServerOptions(opts ...grpc.ServerOption) {
+	s.serverOptions = append(s.serverOptions, opts...)
+}
+
+// IsDraining reports whether the server is draining and should not receive new RPCs.
+func (s *grpcServer) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// ShutdownNotify returns a channel closed when the server stops accepting new RPCs,
+// long-lived streams should watch it and return, GracefulStop waits for them.
+func (s *grpcServer) ShutdownNotify() <-chan struct{} {
+	return s.shutdownCh
+}
+
+// GetServer returns the underlying grpc.Server, it is nil until Start is called.
+func (s *grpcServer) GetServer() *grpc.Server {
+	return s.server
+}
+
+// GetHealthServer returns the grpc.health.v1 server, to set the status of a service manually.
+// It is nil when health is disabled.
+func (s *grpcServer) GetHealthServer() *health.Server {
+	return s.health
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package grpcc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	sctx "github.com/taimaifika/service-context"
+)
+
+// watchHealth runs the health checks of the service context components every health interval
+// and sets the status of the server ("") and of every registered service, until shutdown.
+func (s *grpcServer) watchHealth() {
+	s.checkHealth()
+
+	go func() {
+		ticker := time.NewTicker(s.healthInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.shutdownCh:
+				return
+			case <-ticker.C:
+				s.checkHealth()
+			}
+		}
+	}()
+}
+
+// checkHealth sets every service SERVING when all the components are healthy, NOT_SERVING otherwise.
+func (s *grpcServer) checkHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.healthTimeout)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	var results map[string]error
+	if hr, ok := s.sv.(sctx.HealthReporter); ok {
+		results = hr.HealthCheck(ctx)
+	}
+
+	for id, err := range results {
+		if err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			slog.Warn("grpc health check failed", "id", s.id, "component", id, "error", err)
+		}
+	}
+
+	s.health.SetServingStatus("", status)
+	for _, svc := range s.services {
+		s.health.SetServingStatus(svc.desc.ServiceName, status)
+	}
+}
@@ -0,0 +1,133 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+var ErrMissingToken = errors.New("missing access token")
+
+// TokenParser validates a token and returns its claims, it is implemented by the jwtc component.
+type TokenParser interface {
+	ParseToken(ctx context.Context, tokenString string) (*jwt.RegisteredClaims, error)
+}
+
+// AuthConfig configures the authentication interceptors.
+type AuthConfig struct {
+	// Parser validates the token, usually the jwtc component.
+	Parser TokenParser
+	// Optional lets RPCs without a token through anonymously.
+	// A token that is present but not valid is always rejected.
+	Optional bool
+	// SkipMethods are not authenticated, a trailing * matches a prefix, ex: /grpc.health.v1.Health/*.
+	SkipMethods []string
+}
+
+// UnaryAuth validates the bearer token of the "authorization" metadata and builds a core.Requester
+// from the "sub" and "jti" claims. The requester is stored in the context (core.GetRequester) and
+// its subject is set on the active span as enduser.id. RPCs without a valid token get Unauthenticated.
+func UnaryAuth(cfg AuthConfig) grpc.UnaryServerInterceptor {
+	auth := newAuthenticator(cfg)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if auth.skip.Match(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := auth.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is the stream interceptor of UnaryAuth, the token is checked once when the stream opens.
+func StreamAuth(cfg AuthConfig) grpc.StreamServerInterceptor {
+	auth := newAuthenticator(cfg)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if auth.skip.Match(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := auth.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+type authenticator struct {
+	AuthConfig
+	skip mwutil.Matcher
+}
+
+func newAuthenticator(cfg AuthConfig) *authenticator {
+	if cfg.Parser == nil {
+		panic("auth: token parser is required")
+	}
+
+	return &authenticator{AuthConfig: cfg, skip: mwutil.NewMatcher(cfg.SkipMethods)}
+}
+
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	token := extractToken(ctx)
+	if token == "" {
+		if a.Optional {
+			return ctx, nil
+		}
+		return ctx, unauthenticated(ErrMissingToken)
+	}
+
+	claims, err := a.Parser.ParseToken(ctx, token)
+	if err != nil {
+		return ctx, unauthenticated(err)
+	}
+
+	requester := core.NewRequester(claims.Subject, claims.ID)
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.String("enduser.id", requester.GetSubject()))
+	}
+
+	return core.ContextWithRequester(ctx, requester), nil
+}
+
+// extractToken reads the token from "authorization: Bearer <token>".
+func extractToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, value := range md.Get("authorization") {
+		if scheme, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return ""
+}
+
+// unauthenticated returns the Unauthenticated status, the cause is only sent in debug mode.
+func unauthenticated(cause error) error {
+	msg := core.ErrUnauthorized.Error()
+	if mwutil.DebugEnabled() {
+		msg += ": " + cause.Error()
+	}
+	return status.Error(codes.Unauthenticated, msg)
+}
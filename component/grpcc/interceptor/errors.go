@@ -0,0 +1,182 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// ErrorMapper converts an error into a core error, it returns nil when it does not know the error.
+// The ginc middleware.DriverErrorMapper has this signature.
+type ErrorMapper func(err error) *core.DefaultError
+
+// ErrorsConfig configures the error mapping interceptors.
+type ErrorsConfig struct {
+	// Mappers are tried in order for errors without a status code, the first non nil result is used.
+	Mappers []ErrorMapper
+}
+
+// UnaryErrors converts the errors returned by the handlers to gRPC status errors, see ToStatus.
+func UnaryErrors(cfg ErrorsConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(ctx, err, cfg.Mappers...).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrors is the stream interceptor of UnaryErrors.
+func StreamErrors(cfg ErrorsConfig) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return ToStatus(ss.Context(), err, cfg.Mappers...).Err()
+		}
+		return nil
+	}
+}
+
+// ToStatus converts err to a gRPC status, the counterpart of the HTTP error envelope:
+//   - errors carrying a status (status.Error) are kept as is,
+//   - context cancellations and deadlines become Canceled and DeadlineExceeded,
+//   - core.ValidationError becomes InvalidArgument with the fields in a BadRequest detail,
+//   - errors with a status code (core.DefaultError, core.StatusCodeCarrier) or known by the mappers
+//     get the code of their HTTP status and an ErrorInfo detail with their ID, reason and request ID,
+//   - other errors become Internal with a generic message, the cause is only sent in debug mode.
+func ToStatus(ctx context.Context, err error, mappers ...ErrorMapper) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	}
+
+	rid := core.GetRequestID(ctx)
+
+	if ve := (*core.ValidationError)(nil); errors.As(err, &ve) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(ve.Fields))
+		for i, f := range ve.Fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message, Reason: f.Rule}
+		}
+
+		return withDetails(status.New(codes.InvalidArgument, ve.Message),
+			errorInfo(core.ErrCodeValidationFailed, "", rid),
+			&errdetails.BadRequest{FieldViolations: violations},
+		)
+	}
+
+	if c := core.StatusCodeCarrier(nil); !errors.As(err, &c) || c.StatusCode() == 0 {
+		var mapped *core.DefaultError
+		for _, m := range mappers {
+			if mapped = m(err); mapped != nil {
+				break
+			}
+		}
+
+		switch {
+		case mapped != nil:
+			err = mapped
+		case errors.Is(err, core.ErrRecordNotFound):
+			err = core.ErrNotFound.WithWrap(err)
+		default:
+			err = core.ErrInternalServerError.WithWrap(err)
+		}
+	}
+
+	de := core.ToDefaultError(err, rid)
+
+	id := de.ID()
+	if id == "" {
+		id = mwutil.ErrorCode(de.StatusCode())
+	}
+
+	st := withDetails(status.New(CodeFromHTTPStatus(de.StatusCode()), de.Error()), errorInfo(id, de.Reason(), de.RequestID()))
+
+	if mwutil.DebugEnabled() {
+		debug := de.Debug()
+		if cause := errors.Unwrap(de); debug == "" && cause != nil && cause.Error() != de.Error() {
+			debug = cause.Error()
+		}
+		if debug != "" {
+			st = withDetails(st, &errdetails.DebugInfo{Detail: debug})
+		}
+	}
+
+	return st
+}
+
+// CodeFromHTTPStatus returns the gRPC code of an HTTP status, following the google.rpc.Code mapping.
+func CodeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // client closed request
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if httpStatus >= http.StatusBadRequest && httpStatus < http.StatusInternalServerError {
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+func errorInfo(id, reason, requestID string) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{Reason: id}
+	if reason != "" || requestID != "" {
+		info.Metadata = make(map[string]string, 2)
+	}
+	if reason != "" {
+		info.Metadata["reason"] = reason
+	}
+	if requestID != "" {
+		info.Metadata["request_id"] = requestID
+	}
+	return info
+}
+
+// withDetails adds details to st, st is returned unchanged when they cannot be marshaled.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
@@ -0,0 +1,35 @@
+// Package interceptor provides the gRPC server interceptors of the grpcc component: request ID,
+// logging, panic recovery, authentication and the mapping of core errors to gRPC status codes.
+// Each one comes as a unary and a stream interceptor.
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+)
+
+// serverStream overrides the context of a stream, so the next handlers see the values set by an interceptor.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withContext returns ss with ctx as its context.
+func withContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if s, ok := ss.(*serverStream); ok {
+		return &serverStream{ServerStream: s.ServerStream, ctx: ctx}
+	}
+	return &serverStream{ServerStream: ss, ctx: ctx}
+}
+
+// splitMethod splits a full method name into its service and method, ex: tasks.v1.TaskService and GetTask.
+func splitMethod(fullMethod string) (service, method string) {
+	service, method, _ = strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return service, method
+}
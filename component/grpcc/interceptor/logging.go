@@ -0,0 +1,139 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// LoggingConfig configures the logging interceptors.
+type LoggingConfig struct {
+	// Logger writes the records. Default slog.Default().
+	Logger *slog.Logger
+	// SkipMethods are not logged, a trailing * matches a prefix, ex: /grpc.health.v1.Health/*.
+	SkipMethods []string
+	// Level returns the level of an RPC record. Default error for server errors, warn for
+	// other errors, info otherwise.
+	Level func(code codes.Code) slog.Level
+}
+
+// UnaryLogging logs one record per RPC once it is handled, with the method, status code, numeric
+// latency (latency_ms) and peer, plus the trace, span and request IDs. Use it after the request ID
+// interceptor, the OTel stats handler always runs first.
+func UnaryLogging(cfg LoggingConfig) grpc.UnaryServerInterceptor {
+	log := newRPCLogger(cfg)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if log.skip.Match(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.log(ctx, info.FullMethod, "unary", time.Since(start), err)
+
+		return resp, err
+	}
+}
+
+// StreamLogging is the stream interceptor of UnaryLogging, the record is written when the stream ends.
+func StreamLogging(cfg LoggingConfig) grpc.StreamServerInterceptor {
+	log := newRPCLogger(cfg)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if log.skip.Match(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		kind := "server_stream"
+		switch {
+		case info.IsClientStream && info.IsServerStream:
+			kind = "bidi_stream"
+		case info.IsClientStream:
+			kind = "client_stream"
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+		log.log(ss.Context(), info.FullMethod, kind, time.Since(start), err)
+
+		return err
+	}
+}
+
+type rpcLogger struct {
+	logger *slog.Logger
+	skip   mwutil.Matcher
+	level  func(code codes.Code) slog.Level
+}
+
+func newRPCLogger(cfg LoggingConfig) *rpcLogger {
+	if cfg.Level == nil {
+		cfg.Level = levelByCode
+	}
+
+	return &rpcLogger{
+		logger: cfg.Logger,
+		skip:   mwutil.NewMatcher(cfg.SkipMethods),
+		level:  cfg.Level,
+	}
+}
+
+func (l *rpcLogger) log(ctx context.Context, fullMethod, kind string, latency time.Duration, err error) {
+	st := status.Convert(err)
+	service, method := splitMethod(fullMethod)
+
+	attrs := []slog.Attr{
+		slog.String("service", service),
+		slog.String("method", method),
+		slog.String("type", kind),
+		slog.String("code", st.Code().String()),
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	if rid := core.GetRequestID(ctx); rid != "" {
+		attrs = append(attrs, slog.String("request_id", rid))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", st.Message()))
+	}
+
+	logger := l.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.LogAttrs(ctx, l.level(st.Code()), "RPC", attrs...)
+}
+
+// levelByCode logs the codes a server is responsible for as errors.
+func levelByCode(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented, codes.Unavailable, codes.DeadlineExceeded:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
@@ -0,0 +1,71 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// UnaryRecovery recovers from panics in the next handlers. The panic is logged with its stack, recorded
+// on the RPC span and answered with an Internal status, the panic value is only sent in debug mode.
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoveredError(ctx, info.FullMethod, recovered)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery is the stream interceptor of UnaryRecovery.
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = recoveredError(ss.Context(), info.FullMethod, recovered)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// recoveredError reports a recovered panic and returns the status sent to the client.
+func recoveredError(ctx context.Context, fullMethod string, recovered any) error {
+	stack := debug.Stack()
+	cause, ok := recovered.(error)
+	if !ok {
+		cause = fmt.Errorf("panic: %v", recovered)
+	}
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.RecordError(cause, trace.WithAttributes(attribute.String("exception.stacktrace", string(stack))))
+		span.SetStatus(codes.Error, "panic recovered")
+	}
+
+	slog.ErrorContext(ctx, "Panic recovered",
+		"error", cause.Error(),
+		"method", fullMethod,
+		"stack", string(stack),
+	)
+
+	msg := core.ErrInternalServerError.Error()
+	if mwutil.DebugEnabled() {
+		msg = cause.Error()
+	}
+	return status.Error(grpccodes.Internal, msg)
+}
@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// metadataRequestID is the metadata key of the request ID, gRPC metadata keys are lower case.
+var metadataRequestID = strings.ToLower(core.HeaderRequestID)
+
+// UnaryRequestID reads the x-request-id metadata or generates a UUID v4, stores it in the context
+// (core.GetRequestID), sends it back in the response header and sets it on the active span.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestID is the stream interceptor of UnaryRequestID.
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, withContext(ss, withRequestID(ss.Context())))
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	var rid string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataRequestID); len(values) > 0 {
+			rid = values[0]
+		}
+	}
+	if !mwutil.ValidRequestID(rid) {
+		rid = uuid.NewString()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, rid))
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attribute.String("rpc.request.id", rid))
+	}
+
+	return core.ContextWithRequestID(ctx, rid)
+}
@@ -0,0 +1,191 @@
+package grpcc
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
+
+	"github.com/taimaifika/service-context/component/grpcc/interceptor"
+	"github.com/taimaifika/service-context/component/internal/certreload"
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+)
+
+// healthMethods are the methods of the health service, never traced nor logged.
+var healthMethods = "/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/*"
+
+// Start builds the grpc.Server with the interceptors and the registered services and starts serving
+// in the background. The listener is opened synchronously, so errors like "address already in use" are returned.
+func (s *grpcServer) Start() error {
+	if s.server != nil {
+		return errors.New("grpc server already started")
+	}
+
+	srv, err := s.newServer()
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+
+	for _, svc := range s.services {
+		srv.RegisterService(svc.desc, svc.impl)
+	}
+	if s.health != nil {
+		grpc_health_v1.RegisterHealthServer(srv, s.health)
+		s.watchHealth()
+	}
+	if s.isReflection {
+		reflection.Register(srv)
+	}
+
+	s.server = srv
+	go s.serve(srv, ln)
+
+	slog.Info("grpc server started", "id", s.id, "port", s.port, "services", len(s.services), "tls", s.tlsCertFile != "" && s.tlsKeyFile != "")
+
+	return nil
+}
+
+func (s *grpcServer) serve(srv *grpc.Server, ln net.Listener) {
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		slog.Error("grpc server error", "id", s.id, "port", s.port, "error", err)
+	}
+}
+
+// newServer creates the grpc.Server with limits, keepalive, TLS and interceptors from config.
+// The OTel stats handler runs first, then request ID, logging, recovery, error mapping and the
+// interceptors added with UseUnary and UseStream.
+func (s *grpcServer) newServer() (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(s.maxRecvMsgSize),
+		grpc.MaxSendMsgSize(s.maxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  s.keepaliveTime,
+			Timeout:               s.keepaliveTimeout,
+			MaxConnectionIdle:     s.maxConnectionIdle,
+			MaxConnectionAge:      s.maxConnectionAge,
+			MaxConnectionAgeGrace: s.maxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             s.keepaliveMinTime,
+			PermitWithoutStream: s.keepalivePermitWithoutStream,
+		}),
+	}
+
+	if s.maxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(s.maxConcurrentStreams)))
+	}
+
+	if s.tlsCertFile != "" && s.tlsKeyFile != "" {
+		reloader, err := certreload.New(s.tlsCertFile, s.tlsKeyFile, s.tlsReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		})))
+	}
+
+	if s.isTracing {
+		exclude := mwutil.NewMatcher(append([]string{healthMethods}, splitList(s.tracingExcludeMethods)...))
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
+				return !exclude.Match(info.FullMethodName)
+			}),
+		)))
+	}
+
+	unary := []grpc.UnaryServerInterceptor{interceptor.UnaryRequestID()}
+	stream := []grpc.StreamServerInterceptor{interceptor.StreamRequestID()}
+
+	if s.isLogging {
+		cfg := interceptor.LoggingConfig{SkipMethods: append([]string{healthMethods}, splitList(s.loggingSkipMethods)...)}
+		unary = append(unary, interceptor.UnaryLogging(cfg))
+		stream = append(stream, interceptor.StreamLogging(cfg))
+	}
+
+	unary = append(unary, interceptor.UnaryRecovery(), interceptor.UnaryErrors(interceptor.ErrorsConfig{}))
+	stream = append(stream, interceptor.StreamRecovery(), interceptor.StreamErrors(interceptor.ErrorsConfig{}))
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(append(unary, s.unaryInterceptors...)...),
+		grpc.ChainStreamInterceptor(append(stream, s.streamInterceptors...)...),
+	)
+
+	return grpc.NewServer(append(opts, s.serverOptions...)...), nil
+}
+
+// drain fails the health checks and keeps serving for the drain delay, so the load balancer
+// removes the instance before the server stops accepting RPCs.
+func (s *grpcServer) drain() {
+	if s.server == nil || s.draining.Swap(true) {
+		return
+	}
+
+	// every service is NOT_SERVING from now on, later updates are ignored
+	if s.health != nil {
+		s.health.Shutdown()
+	}
+
+	if s.drainDelay > 0 {
+		slog.Info("draining grpc server...", "id", s.id, "delay", s.drainDelay)
+		time.Sleep(s.drainDelay)
+	}
+}
+
+// notifyShutdown closes the shutdown channel once.
+func (s *grpcServer) notifyShutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdownCh) })
+}
+
+// shutdown stops accepting new RPCs with GracefulStop and waits for in-flight ones.
+// RPCs still running after the shutdown timeout are cancelled by Stop.
+func (s *grpcServer) shutdown() error {
+	if s.server == nil {
+		return nil
+	}
+
+	slog.Info("shutting down grpc server...", "id", s.id, "timeout", s.shutdownTimeout)
+
+	s.notifyShutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		s.server.Stop()
+		<-done
+
+		err := fmt.Errorf("grpc server shutdown: in-flight RPCs still running after %s", s.shutdownTimeout)
+		slog.Error("grpc server shutdown error", "id", s.id, "error", err)
+		return err
+	}
+
+	slog.Info("grpc server stopped", "id", s.id)
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/core"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -22,6 +23,8 @@ type config struct {
 	timeout         time.Duration
 	maxIdleConn     int
 	idleConnTimeout time.Duration
+	// disableCompression stops asking servers for gzip responses
+	disableCompression bool
 }
 
 type HTTPComponent struct {
@@ -46,6 +49,7 @@ func (h *HTTPComponent) InitFlags() {
 	flag.DurationVar(&h.timeout, h.id+"-timeout", time.Second*5, "req http timeout")
 	flag.IntVar(&h.maxIdleConn, h.id+"-max-idle-conn", 100, "max idle connections for http client")
 	flag.DurationVar(&h.idleConnTimeout, h.id+"-idle-conn-timeout", 90*time.Second, "idle connection timeout for http client")
+	flag.BoolVar(&h.disableCompression, h.id+"-disable-compression", false, "do not request gzip responses, by default they are transparently decompressed")
 }
 
 func (h *HTTPComponent) Activate(_ sctx.ServiceContext) error {
@@ -55,7 +59,7 @@ func (h *HTTPComponent) Activate(_ sctx.ServiceContext) error {
 			&http.Transport{
 				MaxIdleConns:       h.config.maxIdleConn,
 				IdleConnTimeout:    h.config.idleConnTimeout,
-				DisableCompression: true,
+				DisableCompression: h.config.disableCompression,
 			},
 		),
 	}
@@ -124,6 +128,11 @@ func (h *HTTPComponent) MakeRequest(ctx context.Context, method string, reqUrl s
 			req.Header.Add(key, value)
 		}
 	}
+
+	// Propagate the request ID to the downstream service
+	if rid := core.GetRequestID(ctx); rid != "" && req.Header.Get(core.HeaderRequestID) == "" {
+		req.Header.Set(core.HeaderRequestID, rid)
+	}
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return nil, err
@@ -202,6 +211,11 @@ func (h *HTTPComponent) MakeRequestWithProxy(ctx context.Context, method string,
 		}
 	}
 
+	// Propagate the request ID to the downstream service
+	if rid := core.GetRequestID(ctx); rid != "" && req.Header.Get(core.HeaderRequestID) == "" {
+		req.Header.Set(core.HeaderRequestID, rid)
+	}
+
 	// Create a new HTTP client with the proxy settings
 	clientWithProxy := &http.Client{
 		Timeout: h.timeout,
@@ -209,7 +223,7 @@ func (h *HTTPComponent) MakeRequestWithProxy(ctx context.Context, method string,
 			&http.Transport{
 				MaxIdleConns:       h.config.maxIdleConn,
 				IdleConnTimeout:    h.config.idleConnTimeout,
-				DisableCompression: true,
+				DisableCompression: h.config.disableCompression,
 				Proxy: func(_ *http.Request) (*url.URL, error) {
 					proxy, err := url.Parse(proxy)
 					if err != nil {
@@ -0,0 +1,72 @@
+package httpserverc
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+	statusDraining    = "draining"
+)
+
+// livezHdl reports the process is alive, it never checks dependencies.
+func (s *httpServer) livezHdl(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": statusOK})
+}
+
+// readyzHdl reports whether the service can receive traffic,
+// based on the draining state and the health checks of the service context components.
+// Failed checks are reported as unavailable and logged, their errors may name hosts or DSNs:
+// they are only sent in debug mode.
+func (s *httpServer) readyzHdl(w http.ResponseWriter, r *http.Request) {
+	// fail fast while draining, so the load balancer stops routing traffic here
+	if s.IsDraining() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": statusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.readyzTimeout)
+	defer cancel()
+
+	status := statusOK
+	checks := make(map[string]string)
+
+	var results map[string]error
+	if hr, ok := s.sv.(sctx.HealthReporter); ok {
+		results = hr.HealthCheck(ctx)
+	}
+
+	for id, err := range results {
+		if err != nil {
+			status = statusUnavailable
+			slog.WarnContext(ctx, "readiness check failed", "component", id, "error", err)
+
+			checks[id] = statusUnavailable
+			if mwutil.DebugEnabled() {
+				checks[id] = err.Error()
+			}
+			continue
+		}
+		checks[id] = statusOK
+	}
+
+	code := http.StatusOK
+	if status != statusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, map[string]any{"status": status, "checks": checks})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
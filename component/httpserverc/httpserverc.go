@@ -0,0 +1,208 @@
+// Package httpserverc is an HTTP server component on the standard library ServeMux, for services not using gin.
+package httpserverc
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/httpserverc/middleware"
+)
+
+const (
+	defaultPort = 8080
+
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+	defaultMaxHeaderBytes    = http.DefaultMaxHeaderBytes
+	defaultShutdownTimeout   = 15 * time.Second
+	defaultTLSReloadInterval = time.Minute
+
+	defaultLivezPath    = "/livez"
+	defaultReadyzPath   = "/readyz"
+	defaultReadyTimeout = 3 * time.Second
+)
+
+type Config struct {
+	port int
+
+	// http.Server timeouts
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+
+	// graceful shutdown deadline
+	shutdownTimeout time.Duration
+	// time to keep serving after readiness fails, before shutdown starts
+	drainDelay time.Duration
+
+	// TLS
+	tlsCertFile       string
+	tlsKeyFile        string
+	tlsReloadInterval time.Duration
+
+	// Health routes
+	isLivez       bool
+	livezPath     string
+	isReadyz      bool
+	readyzPath    string
+	readyzTimeout time.Duration
+
+	// Tracing
+	isTracing             bool
+	tracingExcludePaths   string
+	tracingExcludeMethods string
+}
+
+type httpServer struct {
+	*Config
+	id   string
+	name string
+	sv   sctx.ServiceContext
+
+	mux         *http.ServeMux
+	middlewares []middleware.Middleware
+	server      *http.Server
+
+	// draining is set when Stop is called, readiness fails from then on
+	draining atomic.Bool
+	// shutdownCh is closed when the server stops accepting connections
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewHTTPServer(id string) *httpServer {
+	return &httpServer{
+		Config:     new(Config),
+		id:         id,
+		mux:        http.NewServeMux(),
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+func (s *httpServer) ID() string {
+	return s.id
+}
+
+func (s *httpServer) InitFlags() {
+	flag.IntVar(&s.Config.port, s.id+"-port", defaultPort, "http server port. Default 8080")
+
+	// Server timeouts
+	flag.DurationVar(&s.Config.readTimeout, s.id+"-read-timeout", defaultReadTimeout, "maximum duration for reading the entire request, including the body. Default 30s")
+	flag.DurationVar(&s.Config.readHeaderTimeout, s.id+"-read-header-timeout", defaultReadHeaderTimeout, "maximum duration for reading request headers. Default 10s")
+	flag.DurationVar(&s.Config.writeTimeout, s.id+"-write-timeout", defaultWriteTimeout, "maximum duration before timing out writes of the response. Default 30s")
+	flag.DurationVar(&s.Config.idleTimeout, s.id+"-idle-timeout", defaultIdleTimeout, "maximum time to wait for the next request when keep-alives are enabled. Default 120s")
+	flag.IntVar(&s.Config.maxHeaderBytes, s.id+"-max-header-bytes", defaultMaxHeaderBytes, "maximum number of bytes the server will read parsing the request headers. Default 1048576")
+	flag.DurationVar(&s.Config.shutdownTimeout, s.id+"-shutdown-timeout", defaultShutdownTimeout, "maximum duration to wait for in-flight requests on shutdown. Default 15s")
+	flag.DurationVar(&s.Config.drainDelay, s.id+"-drain-delay", 0, "time to keep serving after readiness starts failing on stop, should cover the load balancer deregistration (e.g. 10s on Kubernetes). Default 0s")
+
+	// TLS
+	flag.StringVar(&s.Config.tlsCertFile, s.id+"-tls-cert-file", "", "path to the TLS certificate file, TLS is enabled when both cert and key are set")
+	flag.StringVar(&s.Config.tlsKeyFile, s.id+"-tls-key-file", "", "path to the TLS private key file")
+	flag.DurationVar(&s.Config.tlsReloadInterval, s.id+"-tls-reload-interval", defaultTLSReloadInterval, "how often the TLS cert/key files are checked for changes. Default 1m")
+
+	// Health routes
+	flag.BoolVar(&s.Config.isLivez, s.id+"-livez-enabled", true, "enable liveness route. Default true")
+	flag.StringVar(&s.Config.livezPath, s.id+"-livez-path", defaultLivezPath, "liveness route path. Default /livez")
+	flag.BoolVar(&s.Config.isReadyz, s.id+"-readyz-enabled", true, "enable readiness route backed by components health checks. Default true")
+	flag.StringVar(&s.Config.readyzPath, s.id+"-readyz-path", defaultReadyzPath, "readiness route path. Default /readyz")
+	flag.DurationVar(&s.Config.readyzTimeout, s.id+"-readyz-timeout", defaultReadyTimeout, "timeout of components health checks in readiness route. Default 3s")
+
+	// Tracing
+	flag.BoolVar(&s.Config.isTracing, s.id+"-tracing-enabled", false, "trace requests with otelhttp and the global providers, named after the service context. Default false")
+	flag.StringVar(&s.Config.tracingExcludePaths, s.id+"-tracing-exclude-paths", "", "comma separated paths not traced, a trailing * matches a prefix (e.g. /ping,/static/*). The health routes are never traced")
+	flag.StringVar(&s.Config.tracingExcludeMethods, s.id+"-tracing-exclude-methods", "", "comma separated methods not traced (e.g. OPTIONS,HEAD)")
+}
+
+func (s *httpServer) Activate(sv sctx.ServiceContext) error {
+	s.name = sv.GetName()
+	s.sv = sv
+
+	if (s.tlsCertFile == "") != (s.tlsKeyFile == "") {
+		return fmt.Errorf("-%s-tls-cert-file and -%s-tls-key-file must be set together", s.id, s.id)
+	}
+
+	if s.isLivez {
+		s.mux.HandleFunc("GET "+s.livezPath, s.livezHdl)
+	}
+	if s.isReadyz {
+		s.mux.HandleFunc("GET "+s.readyzPath, s.readyzHdl)
+	}
+
+	return nil
+}
+
+// Stop drains the server for rolling updates: readiness fails first, the server keeps
+// serving during the drain delay, then it stops accepting new connections and waits
+// for in-flight requests within the shutdown timeout.
+// The service context stops components in reverse registration order, register the server after
+// the datastores its handlers use, otherwise they are closed while requests are still drained.
+func (s *httpServer) Stop() error {
+	s.drain()
+	return s.shutdown()
+}
+
+func (s *httpServer) GetPort() int {
+	return s.port
+}
+
+// GetMux returns the ServeMux, routes use the Go 1.22 patterns, ex: "GET /tasks/{id}".
+func (s *httpServer) GetMux() *http.ServeMux {
+	return s.mux
+}
+
+// Use adds middlewares around the mux, the first one is the outermost. Tracing, when enabled,
+// runs before them. Middlewares added after Start have no effect.
+func (s *httpServer) Use(mws ...middleware.Middleware) {
+	s.middlewares = append(s.middlewares, mws...)
+}
+
+// IsDraining reports whether the server is draining and should not receive new traffic.
+func (s *httpServer) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// ShutdownNotify returns a channel closed when the server stops accepting new connections,
+// long-lived handlers should watch it and end their responses.
+func (s *httpServer) ShutdownNotify() <-chan struct{} {
+	return s.shutdownCh
+}
+
+// GetServer returns the underlying http.Server, it is nil until Start is called.
+func (s *httpServer) GetServer() *http.Server {
+	return s.server
+}
+
+// handler wraps the mux with the middlewares, tracing first so the span covers them.
+func (s *httpServer) handler() http.Handler {
+	mws := s.middlewares
+	if s.isTracing {
+		tracing := middleware.TracingWithConfig(middleware.TracingConfig{
+			ServiceName:    s.name,
+			ExcludePaths:   append([]string{s.livezPath, s.readyzPath}, splitList(s.tracingExcludePaths)...),
+			ExcludeMethods: splitList(s.tracingExcludeMethods),
+		})
+		mws = append([]middleware.Middleware{tracing}, mws...)
+	}
+
+	return middleware.Chain(mws...)(middleware.RouteSpan(s.mux))
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+// Package middleware provides net/http middlewares, func(http.Handler) http.Handler, for the httpserverc
+// component. They also run on gin through the WrapHTTP adapter of the ginc middleware package.
+package middleware
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+const instrumentationName = "github.com/taimaifika/service-context/component/httpserverc/middleware"
+
+// Middleware wraps a handler, it is an alias so plain functions are accepted.
+type Middleware = func(http.Handler) http.Handler
+
+// Chain returns a middleware running mws in order, the first one is the outermost.
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// statusWriter records whether the response has started, for middlewares answering errors.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the buffered response, handlers type asserting http.Flusher (SSE) keep working.
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection, ex: for WebSocket upgrades written against http.Hijacker.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (deadlines, full duplex).
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// Recovery recovers from panics in the next handlers. The panic is logged with its stack, recorded on
+// the request span and answered with the standard error envelope: the status of the panic value when
+// it carries one (core.StatusCodeCarrier), 500 otherwise. http.ErrAbortHandler is re-panicked for net/http.
+func Recovery() Middleware {
+	ec := mwutil.ErrorContext()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				stack := debug.Stack()
+				cause, ok := recovered.(error)
+				if !ok {
+					cause = fmt.Errorf("panic: %v", recovered)
+				}
+
+				ctx := r.Context()
+				if span := trace.SpanFromContext(ctx); span.IsRecording() {
+					span.RecordError(cause, trace.WithAttributes(attribute.String("exception.stacktrace", string(stack))))
+					span.SetStatus(codes.Error, "panic recovered")
+				}
+
+				slog.ErrorContext(ctx, "Panic recovered",
+					"error", cause.Error(),
+					"method", r.Method,
+					"route", r.Pattern,
+					"stack", string(stack),
+				)
+
+				// the client got a partial response, nothing valid can be written anymore
+				if sw.status != 0 {
+					return
+				}
+
+				status := http.StatusInternalServerError
+				var carrier core.StatusCodeCarrier
+				if errors.As(cause, &carrier) && carrier.StatusCode() >= 400 {
+					status = carrier.StatusCode()
+				}
+
+				core.WriteHTTPErrorResponse(w, r, status, ec.CustomError(
+					mwutil.ErrorCode(status), "", http.StatusText(status), cause.Error(),
+				))
+			}()
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+	"github.com/taimaifika/service-context/core"
+)
+
+// RequestIDConfig configures the request ID middleware.
+type RequestIDConfig struct {
+	// Header is the request/response header carrying the request ID. Default X-Request-ID.
+	Header string
+	// Generator creates a request ID when the client does not send a valid one. Default UUID v4.
+	Generator func() string
+}
+
+// RequestID reads the X-Request-ID header or generates a UUID v4, see RequestIDWithConfig.
+func RequestID() Middleware {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig reads the request ID from the header or generates a new one, stores it in the
+// request context (core.GetRequestID), echoes it in the response header and sets it on the active span.
+// Place it after Tracing so the span exists. The ginc RequestID middleware runs it through WrapHTTP.
+// An ID already in the context is kept, ex: the one set by ginc, so it can be used more than once.
+func RequestIDWithConfig(cfg RequestIDConfig) Middleware {
+	if cfg.Header == "" {
+		cfg.Header = core.HeaderRequestID
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = uuid.NewString
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rid := core.GetRequestID(r.Context())
+			if rid == "" {
+				rid = r.Header.Get(cfg.Header)
+			}
+			if !mwutil.ValidRequestID(rid) {
+				rid = cfg.Generator()
+			}
+
+			r = r.WithContext(core.ContextWithRequestID(r.Context(), rid))
+			w.Header().Set(cfg.Header, rid)
+
+			if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+				span.SetAttributes(attribute.String("http.request.id", rid))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/taimaifika/service-context/component/internal/mwutil"
+)
+
+// TracingConfig configures the tracing middleware.
+type TracingConfig struct {
+	// ServiceName is the name of the server in the span attributes, usually the service context name.
+	ServiceName string
+	// TracerProvider creates the spans. Default the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// MeterProvider records the otelhttp server metrics. Default the global meter provider.
+	MeterProvider metric.MeterProvider
+	// Propagators extract the parent span from the request headers. Default the global propagators.
+	Propagators propagation.TextMapPropagator
+	// ExcludePaths are not traced, a trailing * matches a prefix, ex: /debug/pprof/*.
+	ExcludePaths []string
+	// ExcludeMethods are not traced, ex: OPTIONS.
+	ExcludeMethods []string
+}
+
+// Tracing traces requests with the global providers, see TracingWithConfig.
+func Tracing(serviceName string) Middleware {
+	return TracingWithConfig(TracingConfig{ServiceName: serviceName})
+}
+
+// TracingWithConfig starts a server span for each request with otelhttp, named after the method.
+// Wrap the mux with RouteSpan to name it after the matched pattern. Place it first, the span is in
+// the request context of the next middlewares.
+func TracingWithConfig(cfg TracingConfig) Middleware {
+	if cfg.ServiceName == "" {
+		panic("tracing: service name is required")
+	}
+
+	exclude := mwutil.NewMatcher(cfg.ExcludePaths)
+	excludeMethods := make(map[string]bool, len(cfg.ExcludeMethods))
+	for _, m := range cfg.ExcludeMethods {
+		excludeMethods[strings.ToUpper(m)] = true
+	}
+
+	opts := []otelhttp.Option{
+		otelhttp.WithServerName(cfg.ServiceName),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !excludeMethods[r.Method] && !exclude.Match(r.URL.Path)
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	}
+	if cfg.TracerProvider != nil {
+		opts = append(opts, otelhttp.WithTracerProvider(cfg.TracerProvider))
+	}
+	if cfg.MeterProvider != nil {
+		opts = append(opts, otelhttp.WithMeterProvider(cfg.MeterProvider))
+	}
+	if cfg.Propagators != nil {
+		opts = append(opts, otelhttp.WithPropagators(cfg.Propagators))
+	}
+
+	return otelhttp.NewMiddleware(cfg.ServiceName, opts...)
+}
+
+// RouteSpan names the request span after the ServeMux pattern matched by mux, ex: GET /v1/tasks/{id},
+// and sets http.route. The mux sets the pattern on its own copy of the request, once the middlewares
+// changed the request context the tracing middleware does not see it.
+func RouteSpan(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+
+		if r.Pattern == "" {
+			return
+		}
+		if span := trace.SpanFromContext(r.Context()); span.IsRecording() {
+			span.SetName(r.Pattern)
+			span.SetAttributes(attribute.String("http.route", routeOf(r.Pattern)))
+		}
+	})
+}
+
+// routeOf drops the method and host of a pattern, ex: "GET example.com/tasks/{id}" is /tasks/{id}.
+func routeOf(pattern string) string {
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimSpace(path)
+	}
+	if i := strings.Index(pattern, "/"); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
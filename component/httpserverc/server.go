@@ -0,0 +1,126 @@
+package httpserverc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/taimaifika/service-context/component/internal/certreload"
+)
+
+// Start builds the http.Server around the mux and its middlewares and starts serving in the background.
+// The listener is opened synchronously, so errors like "address already in use" are returned.
+func (s *httpServer) Start() error {
+	if s.server != nil {
+		return errors.New("http server already started")
+	}
+
+	srv, err := s.newServer()
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	srv.RegisterOnShutdown(s.notifyShutdown)
+
+	s.server = srv
+	go s.serve(srv, ln)
+
+	slog.Info("http server started", "id", s.id, "port", s.port, "tls", srv.TLSConfig != nil)
+
+	return nil
+}
+
+func (s *httpServer) serve(srv *http.Server, ln net.Listener) {
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("http server error", "id", s.id, "addr", srv.Addr, "error", err)
+	}
+}
+
+// newServer creates the http.Server with timeouts and TLS from config.
+func (s *httpServer) newServer() (*http.Server, error) {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", s.port),
+		Handler:           s.handler(),
+		ReadTimeout:       s.readTimeout,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+		MaxHeaderBytes:    s.maxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+
+	if s.tlsCertFile != "" && s.tlsKeyFile != "" {
+		reloader, err := certreload.New(s.tlsCertFile, s.tlsKeyFile, s.tlsReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+	}
+
+	return srv, nil
+}
+
+// drain fails readiness and keeps serving for the drain delay, so the load balancer
+// removes the instance before the server stops accepting connections.
+func (s *httpServer) drain() {
+	if s.server == nil || s.draining.Swap(true) {
+		return
+	}
+
+	// ask clients to reconnect elsewhere instead of reusing connections to this instance
+	s.server.SetKeepAlivesEnabled(false)
+
+	if s.drainDelay > 0 {
+		slog.Info("draining http server...", "id", s.id, "delay", s.drainDelay)
+		time.Sleep(s.drainDelay)
+	}
+}
+
+// notifyShutdown closes the shutdown channel once, it is called when Shutdown starts.
+func (s *httpServer) notifyShutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdownCh) })
+}
+
+// shutdown stops accepting new connections and waits for in-flight requests.
+// Connections still open after the shutdown timeout are closed forcibly.
+func (s *httpServer) shutdown() error {
+	if s.server == nil {
+		return nil
+	}
+
+	slog.Info("shutting down http server...", "id", s.id, "timeout", s.shutdownTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		err = errors.Join(err, s.server.Close())
+		slog.Error("http server shutdown error", "id", s.id, "error", err)
+		return err
+	}
+
+	slog.Info("http server stopped", "id", s.id)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package certreload serves TLS certificates reloaded from files, shared by the HTTP server components.
+package certreload
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a TLS certificate and reloads it when the cert or key file changes.
+// Files are checked lazily during TLS handshakes, at most once per interval.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu          sync.RWMutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+	lastCheck   time.Time
+}
+
+// New loads the certificate, the files are checked for changes at most once per interval, 0 to never reload.
+func New(certFile, keyFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert := r.cert
+	due := r.interval > 0 && time.Since(r.lastCheck) >= r.interval
+	r.mu.RUnlock()
+
+	if !due {
+		return cert, nil
+	}
+
+	if r.isChanged() {
+		if err := r.reload(); err != nil {
+			// keep serving the previous certificate
+			slog.Error("reload TLS certificate error", "error", err)
+		} else {
+			slog.Info("TLS certificate reloaded", "cert", r.certFile)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// isChanged reports whether the cert or key file modification time differs from the loaded one.
+func (r *Reloader) isChanged() bool {
+	r.mu.Lock()
+	r.lastCheck = time.Now()
+	certModTime, keyModTime := r.certModTime, r.keyModTime
+	r.mu.Unlock()
+
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false
+	}
+
+	return !certInfo.ModTime().Equal(certModTime) || !keyInfo.ModTime().Equal(keyModTime)
+}
+
+func (r *Reloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	r.lastCheck = time.Now()
+
+	return nil
+}
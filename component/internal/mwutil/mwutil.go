@@ -0,0 +1,90 @@
+// Package mwutil holds the helpers shared by the ginc and httpserverc middlewares and the grpcc interceptors.
+package mwutil
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/taimaifika/service-context/core"
+)
+
+// maxRequestIDLength limits the length of a request ID accepted from clients.
+const maxRequestIDLength = 128
+
+// ValidRequestID rejects empty, too long or non printable ASCII IDs, so clients cannot inject into logs.
+func ValidRequestID(rid string) bool {
+	if rid == "" || len(rid) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(rid); i++ {
+		if rid[i] < 0x21 || rid[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ErrorContext returns the global error context, initializing it on first use.
+func ErrorContext() *core.ErrorContext {
+	core.InitGlobalErrorContext()
+	return core.GlobalErrorContext
+}
+
+// ErrorCode returns the error code of an HTTP status, matching the core.ErrorContext helpers,
+// ex: SERVICE_UNAVAILABLE.
+func ErrorCode(status int) string {
+	switch status {
+	case http.StatusInternalServerError:
+		return "INTERNAL_ERROR"
+	case http.StatusNotImplemented:
+		return "SERVICE_NOT_IMPLEMENTED"
+	}
+
+	text := http.StatusText(status)
+	if text == "" {
+		return "INTERNAL_ERROR"
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// Matcher matches exact values and prefixes ending with *, ex: paths like /debug/pprof/*
+// or gRPC methods like /grpc.health.v1.Health/*.
+type Matcher struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+func NewMatcher(patterns []string) Matcher {
+	m := Matcher{exact: make(map[string]bool)}
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			m.prefixes = append(m.prefixes, prefix)
+			continue
+		}
+		m.exact[p] = true
+	}
+	return m
+}
+
+// Match reports whether s is one of the exact values or starts with a prefix, an empty s never matches.
+func (m Matcher) Match(s string) bool {
+	if s == "" {
+		return false
+	}
+	if m.exact[s] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugEnabled reports whether error causes may be sent to clients, see core.SetGlobalDebugContext.
+func DebugEnabled() bool {
+	return core.GlobalDebugContext != nil && core.GlobalDebugContext.IsDebugEnabled()
+}
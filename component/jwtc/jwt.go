@@ -19,6 +19,7 @@ const (
 var (
 	ErrSecretKeyNotValid     = errors.New("secret key must be in 32 bytes")
 	ErrTokenLifeTimeTooShort = errors.New("token life time too short")
+	ErrTokenNotValid         = errors.New("token is not valid")
 )
 
 type jwtx struct {
@@ -38,14 +39,14 @@ func (j *jwtx) ID() string {
 func (j *jwtx) InitFlags() {
 	flag.StringVar(
 		&j.secret,
-		"jwt-secret",
+		j.id+"-secret",
 		defaultSecret,
 		"Secret key to sign JWT",
 	)
 
 	flag.IntVar(
 		&j.expireTokenInSeconds,
-		"jwt-exp-secs",
+		j.id+"-exp-secs",
 		defaultExpireTokenInSeconds,
 		"Number of seconds token will expired",
 	)
@@ -100,9 +101,14 @@ func (j *jwtx) ParseToken(ctx context.Context, tokenString string) (claims *jwt.
 		return []byte(j.secret), nil
 	})
 
-	if !token.Valid {
+	// token is nil when the token string is malformed
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	if token == nil || !token.Valid {
+		return nil, errors.WithStack(ErrTokenNotValid)
+	}
+
 	return &rc, nil
 }
@@ -75,6 +75,11 @@ func (m *mongoDbComponent) GetCollectionWithDatabase(dbName, collectionName stri
 	return m.mongoClient.Database(dbName).Collection(collectionName)
 }
 
+// HealthCheck pings the primary, it implements sctx.HealthChecker.
+func (m *mongoDbComponent) HealthCheck(ctx context.Context) error {
+	return m.mongoClient.Ping(ctx, nil)
+}
+
 func (m *mongoDbComponent) ID() string {
 	return m.id
 }
@@ -5,8 +5,13 @@ import (
 	"errors"
 	"flag"
 	"log/slog"
+	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
@@ -15,6 +20,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
@@ -27,6 +33,7 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 
 	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/slogc"
 )
 
 // Default values for configuration.
@@ -64,6 +71,10 @@ type config struct {
 	isEnabledTrace  bool
 	isEnabledMetric bool
 	isEnabledLog    bool
+
+	// metric readers
+	isEnabledMetricPush bool
+	isEnabledPrometheus bool
 }
 
 type otelComponent struct {
@@ -74,6 +85,9 @@ type otelComponent struct {
 	ctx context.Context
 
 	shutdown func(context.Context) error
+
+	// metricsHandler serves the metrics in the Prometheus format, nil when disabled
+	metricsHandler http.Handler
 }
 
 func NewOtel(id string) *otelComponent {
@@ -111,6 +125,10 @@ func (oc *otelComponent) InitFlags() {
 	flag.BoolVar(&oc.isEnabledTrace, oc.prefix+"-is-enabled-trace", true, "Enable otel trace")
 	flag.BoolVar(&oc.isEnabledMetric, oc.prefix+"-is-enabled-metric", true, "Enable otel metric")
 	flag.BoolVar(&oc.isEnabledLog, oc.prefix+"-is-enabled-log", true, "Enable otel log")
+
+	// metric readers
+	flag.BoolVar(&oc.isEnabledMetricPush, oc.prefix+"-is-enabled-metric-push", true, "Push metrics to the otlp endpoint (or console)")
+	flag.BoolVar(&oc.isEnabledPrometheus, oc.prefix+"-is-enabled-prometheus", false, "Expose metrics in the Prometheus format, served by ginc on the metrics route")
 }
 
 func (oc *otelComponent) Activate(sv sctx.ServiceContext) error {
@@ -134,6 +152,12 @@ func (oc *otelComponent) Activate(sv sctx.ServiceContext) error {
 	return nil
 }
 
+// MetricsHandler serves the metrics of the meter provider in the Prometheus format,
+// it is nil when the Prometheus exporter is disabled.
+func (oc *otelComponent) MetricsHandler() http.Handler {
+	return oc.metricsHandler
+}
+
 func (oc *otelComponent) Stop() error {
 	oc.shutdown(oc.ctx)
 	return nil
@@ -223,7 +247,7 @@ func (oc *otelComponent) setupOTelSdk() (shutdown func(context.Context) error, e
 
 		if oc.isOtlpProtocolEnabled() {
 			slog.Info("Using OTLP log exporter")
-			slog.SetDefault(slog.New(otelslog.NewHandler(oc.serviceName, otelslog.WithLoggerProvider(loggerProvider))))
+			slog.SetDefault(slog.New(slogc.NewContextHandler(otelslog.NewHandler(oc.serviceName, otelslog.WithLoggerProvider(loggerProvider)))))
 		}
 	}
 	return
@@ -307,32 +331,45 @@ func (oc *otelComponent) newResource() *resource.Resource {
 
 // newMeterProvider creates a new meter provider.
 func (oc *otelComponent) newMeterProvider() (*metric.MeterProvider, error) {
-	var metricExporter metric.Exporter
-	if oc.isOtlpProtocolEnabled() {
-		// Exporter to otlp
-		otlpMetricExporter, err := oc.newOtlpMetricExporter()
-		if err != nil {
-			return nil, err
+	opts := []metric.Option{metric.WithResource(oc.newResource())}
+
+	if oc.isEnabledMetricPush {
+		var metricExporter metric.Exporter
+		if oc.isOtlpProtocolEnabled() {
+			// Exporter to otlp
+			otlpMetricExporter, err := oc.newOtlpMetricExporter()
+			if err != nil {
+				return nil, err
+			}
+			metricExporter = otlpMetricExporter
+		} else {
+			// Exporter to stdout
+			stdoutMetricExporter, err := stdoutmetric.New()
+			if err != nil {
+				return nil, err
+			}
+			metricExporter = stdoutMetricExporter
 		}
-		metricExporter = otlpMetricExporter
-	} else {
-		// Exporter to stdout
-		stdoutMetricExporter, err := stdoutmetric.New()
+
+		opts = append(opts, metric.WithReader(metric.NewPeriodicReader(metricExporter,
+			// Default is 1m. Set to 3s for demonstrative purposes.
+			metric.WithInterval(3*time.Second))))
+	}
+
+	// Reader collecting on scrape, the registry also exposes the Go runtime and process metrics.
+	if oc.isEnabledPrometheus {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+		promExporter, err := otelprom.New(otelprom.WithRegisterer(registry))
 		if err != nil {
 			return nil, err
 		}
-		metricExporter = stdoutMetricExporter
+		opts = append(opts, metric.WithReader(promExporter))
+		oc.metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
 	}
 
-	res := oc.newResource()
-
-	meterProvider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter,
-			// Default is 1m. Set to 3s for demonstrative purposes.
-			metric.WithInterval(3*time.Second))),
-		metric.WithResource(res),
-	)
-	return meterProvider, nil
+	return metric.NewMeterProvider(opts...), nil
 }
 
 // newOtlpMetricExporter creates a new OTLP metric exporter. (gRPC or HTTP)
@@ -40,10 +40,10 @@ func NewRedisComponent(id string) *redisComponent {
 	}
 }
 
-func (r *redisComponent) healthCheck() error {
-	// health check
+// HealthCheck pings redis, it implements sctx.HealthChecker.
+func (r *redisComponent) HealthCheck(ctx context.Context) error {
 	// ping redis
-	_, err := r.redis.Ping(context.Background()).Result()
+	_, err := r.redis.Ping(ctx).Result()
 	if err != nil {
 		return err
 	}
@@ -104,7 +104,7 @@ func (r *redisComponent) Activate(ctx sctx.ServiceContext) error {
 	slog.Info("Connect to redis...")
 
 	// health check
-	err := r.healthCheck()
+	err := r.HealthCheck(context.Background())
 	if err != nil {
 		return err
 	}
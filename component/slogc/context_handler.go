@@ -0,0 +1,45 @@
+package slogc
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/taimaifika/service-context/core"
+)
+
+// contextHandler adds values carried by the context (request ID) to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps a handler so records logged with a context (slog.InfoContext...)
+// get the request ID stored by the request ID middleware.
+func NewContextHandler(next slog.Handler) slog.Handler {
+	if _, ok := next.(*contextHandler); ok {
+		return next
+	}
+	return &contextHandler{Handler: next}
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if rid := core.GetRequestID(ctx); rid != "" && !hasAttr(r, "request_id") {
+		r.AddAttrs(slog.String("request_id", rid))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func hasAttr(r slog.Record, key string) (found bool) {
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == key
+		return !found
+	})
+	return found
+}
@@ -23,9 +23,17 @@ type slogComponent struct {
 	opts    *slog.HandlerOptions
 }
 
+const defaultID = "slog"
+
+// NewSlogComponent creates the slog component with the default "slog" ID.
 func NewSlogComponent() *slogComponent {
+	return NewSlogComponentWithID(defaultID)
+}
+
+// NewSlogComponentWithID creates the slog component with a custom ID, flags are prefixed by the ID.
+func NewSlogComponentWithID(id string) *slogComponent {
 	return &slogComponent{
-		id:     "slog",
+		id:     id,
 		config: new(config),
 		opts:   &slog.HandlerOptions{},
 	}
@@ -68,8 +76,8 @@ func (s *slogComponent) Activate(_ sctx.ServiceContext) error {
 	// set log format
 	s.SetLogFormat(s.logFormat)
 
-	// create slog logger
-	logger := slog.New(s.handler)
+	// create slog logger, records get the request ID from the context
+	logger := slog.New(NewContextHandler(s.handler))
 
 	// set slog default logger
 	slog.SetDefault(logger)
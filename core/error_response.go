@@ -335,11 +335,29 @@ var ErrUnsupportedMediaType = DefaultError{
 	CodeField:   http.StatusUnsupportedMediaType,
 }
 
+var ErrRequestEntityTooLarge = DefaultError{
+	StatusField: http.StatusText(http.StatusRequestEntityTooLarge),
+	ErrorField:  "The request body is too large",
+	CodeField:   http.StatusRequestEntityTooLarge,
+}
+
 var ErrConflict = DefaultError{
 	StatusField: http.StatusText(http.StatusConflict),
 	ErrorField:  "The resource could not be created due to a conflict",
 	CodeField:   http.StatusConflict,
 }
 
+var ErrServiceUnavailable = DefaultError{
+	StatusField: http.StatusText(http.StatusServiceUnavailable),
+	ErrorField:  "The service is temporarily unavailable, please try again later",
+	CodeField:   http.StatusServiceUnavailable,
+}
+
+var ErrGatewayTimeout = DefaultError{
+	StatusField: http.StatusText(http.StatusGatewayTimeout),
+	ErrorField:  "The request took too long to complete",
+	CodeField:   http.StatusGatewayTimeout,
+}
+
 // ErrRecordNotFound is used to make our application logic independent of other libraries errors
 var ErrRecordNotFound = errors.New("record not found")
@@ -1,10 +1,10 @@
 package core
 
 type Paging struct {
-	Page       int    `json:"page" form:"page"`
-	Limit      int    `json:"limit" form:"limit"`
+	Page       int    `json:"page" form:"page" doc:"page number, from 1"`
+	Limit      int    `json:"limit" form:"limit" doc:"items per page, 10 by default and at most 200"`
 	Total      int64  `json:"total" form:"-"`
-	FakeCursor string `json:"cursor" form:"cursor"`
+	FakeCursor string `json:"cursor" form:"cursor" doc:"cursor of the page, replaces the page number"`
 	NextCursor string `json:"next_cursor"`
 }
 
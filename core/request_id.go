@@ -0,0 +1,25 @@
+package core
+
+import "context"
+
+// HeaderRequestID is the header carrying the request ID between services.
+const HeaderRequestID = "X-Request-ID"
+
+const KeyRequestID contextKey = "request_id"
+
+// GetRequestID returns the request ID stored in the context, or an empty string.
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	if rid, ok := ctx.Value(KeyRequestID).(string); ok {
+		return rid
+	}
+
+	return ""
+}
+
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, KeyRequestID, requestID)
+}
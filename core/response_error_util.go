@@ -2,10 +2,12 @@ package core
 
 // Error Response Structures
 type ErrorDetail struct {
-	Code        string `json:"code"`
-	Title       string `json:"title,omitempty"` // optional title for the error
-	Message     string `json:"message"`
-	Description string `json:"description,omitempty"` // debug only
+	Code        string       `json:"code"`
+	Title       string       `json:"title,omitempty"` // optional title for the error
+	Message     string       `json:"message"`
+	Description string       `json:"description,omitempty"` // debug only
+	RequestID   string       `json:"request_id,omitempty"`  // request ID, see GetRequestID
+	Details     []FieldError `json:"details,omitempty"`     // invalid fields, see ValidationError
 }
 
 type StandardResponse struct {
@@ -1,6 +1,12 @@
 package core
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
 
 // NewSuccessResponse creates a new success response with optional action code
 func NewSuccessResponse(data interface{}, actionCode ...string) StandardResponse {
@@ -39,6 +45,35 @@ func NewErrorResponse(code, title, message, description string, showDebug bool,
 }
 
 // WriteStandardErrorResponse writes error using the new standard format
+// The request ID of the request context is filled into the error details.
 func WriteStandardErrorResponse(c *gin.Context, httpStatus int, errResponse StandardResponse) {
+	if c.Request != nil {
+		errResponse = withRequestID(c.Request.Context(), errResponse)
+	}
+
 	c.JSON(httpStatus, errResponse)
 }
+
+// WriteHTTPErrorResponse is WriteStandardErrorResponse for net/http handlers.
+func WriteHTTPErrorResponse(w http.ResponseWriter, r *http.Request, httpStatus int, errResponse StandardResponse) {
+	if r != nil {
+		errResponse = withRequestID(r.Context(), errResponse)
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(httpStatus)
+	_ = json.NewEncoder(w).Encode(errResponse)
+}
+
+// withRequestID fills the request ID of ctx into the error details, the details are copied.
+func withRequestID(ctx context.Context, errResponse StandardResponse) StandardResponse {
+	if errResponse.Error != nil && errResponse.Error.RequestID == "" {
+		if rid := GetRequestID(ctx); rid != "" {
+			detail := *errResponse.Error
+			detail.RequestID = rid
+			errResponse.Error = &detail
+		}
+	}
+
+	return errResponse
+}
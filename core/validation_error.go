@@ -0,0 +1,58 @@
+package core
+
+import (
+	"net/http"
+	"strings"
+)
+
+const ErrCodeValidationFailed = "VALIDATION_FAILED"
+
+// FieldError describes an invalid field of a request.
+type FieldError struct {
+	// Field is the path of the field as sent by the client, ex: items[0].name
+	Field string `json:"field"`
+	// Rule is the failed rule, ex: required, max, type
+	Rule string `json:"rule"`
+	// Param is the parameter of the rule, ex: 255 for max=255
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError is a 400 error listing the invalid fields of a request,
+// it is rendered with the fields in the error details.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+// NewValidationError creates a validation error of fields.
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Message: "The request contains invalid fields", Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return e.Message + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+func (e *ValidationError) ID() string {
+	return ErrCodeValidationFailed
+}
+
+// ValidationError creates a new StandardResponse with the invalid fields in the details
+func (ec *ErrorContext) ValidationError(message string, fields []FieldError) StandardResponse {
+	resp := NewErrorResponse(ErrCodeValidationFailed, "", message, "", ec.debugEnabled)
+	resp.Error.Details = fields
+	return resp
+}
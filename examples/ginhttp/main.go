@@ -1,25 +1,22 @@
 package main
 
 import (
-	"context"
-	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	sctx "github.com/taimaifika/service-context"
 	"github.com/taimaifika/service-context/component/ginc"
-	"github.com/taimaifika/service-context/component/ginc/middleware"
 )
 
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 func main() {
@@ -39,28 +36,18 @@ func main() {
 	router := comp.GetRouter()
 	router.Use(
 		gin.Logger(),
-		middleware.AllowCORS(),
 		gin.Recovery(),
 	)
 
 	// Demo serve a handler with service-context
 	router.GET("/demo", demoHdl(serviceCtx))
 
-	// Source code from: https://gin-gonic.com/docs/examples/graceful-restart-or-stop/
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", comp.GetPort()),
-		Handler: router,
+	// ginc builds the http.Server with timeouts/TLS from flags
+	if err := comp.Start(); err != nil {
+		log.Fatal(err)
 	}
 
-	go func() {
-		// service connections
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("listen error", slog.String("error", err.Error()))
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
+	// Wait for interrupt signal to gracefully shutdown the server.
 	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscanll.SIGTERM
 	// kill -2 is syscall.SIGINT
@@ -70,17 +57,10 @@ func main() {
 
 	slog.Info("Shutdown Server ...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
+	// Stop drains in-flight requests within gin-shutdown-timeout
+	if err := serviceCtx.Stop(); err != nil {
 		slog.Error("Server Shutdown", slog.String("error", err.Error()))
 	}
-
-	<-ctx.Done()
-	slog.Info("timeout of 5 seconds.")
-
-	_ = serviceCtx.Stop()
 	slog.Info("Server exited")
 }
 
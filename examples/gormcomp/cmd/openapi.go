@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+
+	taskapi "github.com/taimaifika/service-context/examples/gormcomp/services/task/transport/api"
+)
+
+var openapiOutput string
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Write the OpenAPI document of the service to a file",
+	Run: func(cmd *cobra.Command, args []string) {
+		gin.SetMode(gin.ReleaseMode)
+
+		// the handlers are not called, the document only needs the routes: no database is required
+		api := newAPI(gin.New(), taskapi.NewApi(nil, nil))
+
+		if err := api.WriteFile(openapiOutput); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	openapiCmd.Flags().StringVarP(&openapiOutput, "output", "o", "openapi.json", "output file, - for stdout")
+}
@@ -7,16 +7,18 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"gorm.io/gorm"
 
 	"github.com/taimaifika/service-context/component/ginc"
 	"github.com/taimaifika/service-context/component/ginc/middleware"
+	"github.com/taimaifika/service-context/component/ginc/openapi"
 	"github.com/taimaifika/service-context/component/gormc"
 	"github.com/taimaifika/service-context/component/otelc"
 	"github.com/taimaifika/service-context/component/slogc"
@@ -32,14 +34,16 @@ func newServiceCtx() sctx.ServiceContext {
 		sctx.WithName(serviceContextName),
 		sctx.WithComponent(slogc.NewSlogComponent()),
 		sctx.WithComponent(otelc.NewOtel("otel")),
-		sctx.WithComponent(ginc.NewGin("gin")),
 		sctx.WithComponent(gormc.NewGormDB("postgres", "postgres")),
+		// servers last, they are stopped first and drained while the database is still open
+		sctx.WithComponent(ginc.NewGin("gin")),
 	)
 }
 
 type GINginComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 type GormComponent interface {
@@ -67,14 +71,12 @@ var rootCmd = &cobra.Command{
 
 		router := ginComp.GetRouter()
 
+		// requests are traced (GIN_TRACING_ENABLED) and given a request ID by ginc
 		router.Use(
 			gin.Logger(), // format log to text
 			middleware.Logger(),
-			middleware.Recovery(serviceCtx),
-			otelgin.Middleware(
-				serviceContextName,
-				otelgin.WithTracerProvider(otel.GetTracerProvider()),
-			),
+			middleware.Recovery(),
+			middleware.ErrorHandler(),
 		)
 
 		// ping endpoint
@@ -115,20 +117,42 @@ var rootCmd = &cobra.Command{
 			c.JSON(http.StatusOK, gin.H{"data": num})
 		})
 
-		// task service
-		taskApiService := composer.ComposeTaskApiService(serviceCtx)
-		tasks := router.Group("/tasks")
-		{
-			tasks.GET("", taskApiService.ListTaskHdl())
-		}
+		// task service, documented at /openapi.json and /docs
+		api := newAPI(router, composer.ComposeTaskApiService(serviceCtx))
+		api.Serve(router, openapi.ServeConfig{UI: openapi.UISwagger})
 
 		// start the server
-		if err := router.Run(fmt.Sprintf(":%d", ginComp.GetPort())); err != nil {
+		if err := ginComp.Start(); err != nil {
 			log.Fatal(err)
 		}
+
+		// graceful shutdown
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+
+		if err := serviceCtx.Stop(); err != nil {
+			slog.Error("server shutdown error", "error", err)
+		}
 	},
 }
 
+// newAPI registers the typed routes of the services, the openapi command uses it to write the document.
+func newAPI(router gin.IRouter, taskService composer.TaskService) *openapi.API {
+	api := openapi.New(router, openapi.Info{Title: serviceContextName, Version: "1.0.0"})
+
+	tasks := api.Group("/tasks").WithTags("tasks")
+	openapi.Get(tasks, "", taskService.ListTasks, openapi.Summary("List tasks"))
+	// mobile clients retry on flaky networks, the Idempotency-Key header prevents duplicates
+	openapi.Post(tasks, "", taskService.CreateTask,
+		openapi.Summary("Create a task"),
+		openapi.Errors(http.StatusConflict),
+		openapi.Middlewares(middleware.Idempotency()),
+	)
+
+	return api
+}
+
 func testPanic(ctx context.Context) {
 	_, span := otel.Tracer("service-context-gorm").Start(ctx, "testPanic")
 	defer span.End()
@@ -147,6 +171,7 @@ func testPanic(ctx context.Context) {
 
 func Execute() {
 	rootCmd.AddCommand(outEnvCmd)
+	rootCmd.AddCommand(openapiCmd)
 	slog.Info("Starting application")
 
 	if err := rootCmd.Execute(); err != nil {
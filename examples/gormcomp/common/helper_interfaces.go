@@ -8,6 +8,7 @@ import (
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 type GormComponent interface {
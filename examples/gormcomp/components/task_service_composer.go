@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	sctx "github.com/taimaifika/service-context"
 	"github.com/taimaifika/service-context/examples/gormcomp/common"
+	"github.com/taimaifika/service-context/examples/gormcomp/services/task/entity"
 
 	taskbiz "github.com/taimaifika/service-context/examples/gormcomp/services/task/biz"
 	taskrepo "github.com/taimaifika/service-context/examples/gormcomp/services/task/repository/pg"
@@ -11,7 +12,8 @@ import (
 )
 
 type TaskService interface {
-	ListTaskHdl() func(*gin.Context)
+	ListTasks(c *gin.Context, req *struct{}) ([]entity.Task, error)
+	CreateTask(c *gin.Context, req *entity.TaskCreateRequest) (*entity.Task, error)
 }
 
 func ComposeTaskApiService(serviceCtx sctx.ServiceContext) TaskService {
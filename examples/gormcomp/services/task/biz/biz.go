@@ -9,6 +9,7 @@ import (
 
 type TaskRepository interface {
 	ListTasks(ctx context.Context, filter *entity.Filter, paging *core.Paging) ([]entity.Task, error)
+	AddNewTask(ctx context.Context, data *entity.Task) error
 }
 
 type biz struct {
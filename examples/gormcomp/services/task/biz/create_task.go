@@ -0,0 +1,30 @@
+package biz
+
+import (
+	"context"
+
+	"github.com/taimaifika/service-context/core"
+	"github.com/taimaifika/service-context/examples/gormcomp/services/task/entity"
+	"go.opentelemetry.io/otel"
+)
+
+func (b *biz) CreateTask(ctx context.Context, data *entity.TaskCreateRequest) (*entity.Task, error) {
+	ctx, span := otel.Tracer(b.tracerName).Start(ctx, "CreateTask")
+	defer span.End()
+
+	if err := data.Validate(); err != nil {
+		return nil, core.ErrBadRequest.WithError(err.Error())
+	}
+
+	task := &entity.Task{
+		Title:       data.Title,
+		Description: data.Description,
+		Status:      entity.TaskType(data.Status),
+	}
+
+	if err := b.taskRepo.AddNewTask(ctx, task); err != nil {
+		return nil, core.ErrInternalServerError.WithError(entity.ErrCannotCreateTask.Error()).WithWrap(err)
+	}
+
+	return task, nil
+}
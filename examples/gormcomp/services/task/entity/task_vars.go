@@ -7,9 +7,9 @@ import (
 // TaskCreateRequest is a struct that represents the request to create a new task
 type TaskCreateRequest struct {
 	// core.SQLModel
-	Title       string `json:"title"`
+	Title       string `json:"title" binding:"required,max=255"`
 	Description string `json:"description"`
-	Status      string `json:"status"`
+	Status      string `json:"status" doc:"doing or done"`
 }
 
 func (TaskCreateRequest) TableName() string {
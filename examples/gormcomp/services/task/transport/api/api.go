@@ -10,6 +10,7 @@ import (
 
 type Biz interface {
 	ListTasks(ctx context.Context, filter *entity.Filter, paging *core.Paging) ([]entity.Task, error)
+	CreateTask(ctx context.Context, data *entity.TaskCreateRequest) (*entity.Task, error)
 }
 
 type api struct {
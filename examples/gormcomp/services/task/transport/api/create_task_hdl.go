@@ -0,0 +1,11 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/taimaifika/service-context/examples/gormcomp/services/task/entity"
+)
+
+// CreateTask handles the request to create a task, see openapi.Handler
+func (a *api) CreateTask(c *gin.Context, req *entity.TaskCreateRequest) (*entity.Task, error) {
+	return a.biz.CreateTask(c.Request.Context(), req)
+}
@@ -1,24 +1,12 @@
 package api
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
-	"github.com/taimaifika/service-context/core"
+	"github.com/taimaifika/service-context/examples/gormcomp/services/task/entity"
 )
 
-// ListTaskHdl handles the request to list tasks
-func (a *api) ListTaskHdl() func(*gin.Context) {
-	return func(c *gin.Context) {
-		tasks, err := a.biz.ListTasks(c.Request.Context(), nil, nil)
-		if err != nil {
-			core.WriteStandardErrorResponse(c, http.StatusInternalServerError, core.GlobalErrorContext.InternalServerError(
-				core.ErrInternalServerError.Error(),
-				err.Error(),
-			))
-			return
-		}
-
-		core.WriteSuccessResponse(c, tasks)
-	}
+// ListTasks handles the request to list tasks, see openapi.Handler.
+// It takes no query parameters, the repository does not filter nor page yet.
+func (a *api) ListTasks(c *gin.Context, _ *struct{}) ([]entity.Task, error) {
+	return a.biz.ListTasks(c.Request.Context(), nil, nil)
 }
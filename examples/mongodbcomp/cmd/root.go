@@ -19,7 +19,6 @@ import (
 	"github.com/taimaifika/service-context/component/slogc"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	sctx "github.com/taimaifika/service-context"
 )
@@ -32,10 +31,10 @@ func newServiceCtx() sctx.ServiceContext {
 		sctx.WithComponent(slogc.NewSlogComponent()),
 		// observability (traces/metrics/log export)
 		sctx.WithComponent(otelc.NewOtel("otel")),
-		// http server (gin) - optional for this example, initialized but not started
-		sctx.WithComponent(ginc.NewGin("gin")),
 		// mongodb component
 		sctx.WithComponent(mongodbc.NewMongoDbComponent("mongodb")),
+		// http server (gin) - registered last so it is stopped first, while mongodb is still open
+		sctx.WithComponent(ginc.NewGin("gin")),
 	)
 }
 
@@ -47,6 +46,7 @@ type MongoDbComponent interface {
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 var rootCmd = &cobra.Command{
@@ -64,12 +64,10 @@ var rootCmd = &cobra.Command{
 		ginComp := serviceCtx.MustGet("gin").(GINComponent)
 
 		router := ginComp.GetRouter()
-		// middlewares
+		// middlewares, requests are traced (GIN_TRACING_ENABLED) and given a request ID by ginc
 		router.Use(
 			middleware.Logger(),
-			middleware.AllowCORS(),
-			middleware.Recovery(serviceCtx),
-			otelgin.Middleware(serviceContextName),
+			middleware.Recovery(),
 		)
 
 		db := mongoComp.GetMongoClient().Database(mongoComp.GetDatabaseName())
@@ -80,10 +78,7 @@ var rootCmd = &cobra.Command{
 			Test string      `json:"test" bson:"test"`
 		}
 
-		// health check
-		router.GET("/health", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
-		})
+		// health checks are served by ginc at /livez and /readyz
 
 		// list documents
 		router.GET("/tests", func(c *gin.Context) {
@@ -171,15 +166,10 @@ var rootCmd = &cobra.Command{
 			c.JSON(http.StatusOK, gin.H{"deleted": res.DeletedCount})
 		})
 
-		srv := &http.Server{Addr: fmt.Sprintf(":%d", ginComp.GetPort()), Handler: router}
-
-		go func() {
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				slog.Error("listen error", "error", err)
-			}
-		}()
-
-		slog.Info("server started", "port", ginComp.GetPort())
+		if err := ginComp.Start(); err != nil {
+			slog.Error("start server error", "error", err)
+			panic(err)
+		}
 
 		// graceful shutdown
 		quit := make(chan os.Signal, 1)
@@ -187,12 +177,10 @@ var rootCmd = &cobra.Command{
 		<-quit
 		slog.Info("shutting down server...")
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := srv.Shutdown(ctx); err != nil {
+		// components stop in reverse registration order: gin is drained first, then the datastores are closed
+		if err := serviceCtx.Stop(); err != nil {
 			slog.Error("server shutdown error", "error", err)
 		}
-		_ = serviceCtx.Stop()
 		slog.Info("server exited")
 	},
 }
@@ -5,13 +5,14 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
 	sctx "github.com/taimaifika/service-context"
 	"github.com/taimaifika/service-context/component/ginc"
 	"github.com/taimaifika/service-context/component/otelc"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -19,14 +20,15 @@ import (
 func newServiceCtx() sctx.ServiceContext {
 	return sctx.NewServiceContext(
 		sctx.WithName("otel-component"),
-		sctx.WithComponent(ginc.NewGin("gin")),
 		sctx.WithComponent(otelc.NewOtel("otel")),
+		sctx.WithComponent(ginc.NewGin("gin")),
 	)
 }
 
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 var rootCmd = &cobra.Command{
@@ -44,7 +46,8 @@ var rootCmd = &cobra.Command{
 
 		router := comp.GetRouter()
 
-		router.Use(gin.Recovery(), gin.Logger(), otelgin.Middleware(getHostname()))
+		// requests are traced by ginc (GIN_TRACING_ENABLED)
+		router.Use(gin.Recovery(), gin.Logger())
 
 		router.GET("/ping", func(c *gin.Context) {
 			ctx := c.Request.Context()
@@ -63,21 +66,20 @@ var rootCmd = &cobra.Command{
 			c.JSON(http.StatusOK, gin.H{"data": "pong"})
 		})
 
-		if err := router.Run(fmt.Sprintf(":%d", comp.GetPort())); err != nil {
-			slog.Error("router execution failed", slog.Any("error", err))
+		if err := comp.Start(); err != nil {
+			slog.Error("server start failed", slog.Any("error", err))
 			os.Exit(1)
 		}
-	},
-}
 
-// getHostname returns the hostname of the machine
-func getHostname() string {
-	hostname, err := os.Hostname()
-	if err != nil {
-		slog.Error("failed to get hostname", slog.Any("error", err))
-		os.Exit(1)
-	}
-	return hostname
+		// graceful shutdown
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+
+		if err := serviceCtx.Stop(); err != nil {
+			slog.Error("server shutdown failed", slog.Any("error", err))
+		}
+	},
 }
 
 func Execute() {
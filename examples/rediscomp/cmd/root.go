@@ -12,7 +12,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	sctx "github.com/taimaifika/service-context"
 	"github.com/taimaifika/service-context/component/ginc"
@@ -31,8 +30,10 @@ func newServiceCtx() sctx.ServiceContext {
 	return sctx.NewServiceContext(
 		sctx.WithComponent(slogc.NewSlogComponent()),
 		sctx.WithComponent(otelc.NewOtel("otel")),
-		sctx.WithComponent(ginc.NewGin("gin")),
 		sctx.WithComponent(redisc.NewRedisComponent(common.KeyCompRedis)),
+		sctx.WithComponent(composer.NewCacheModule(common.KeyCompCache)),
+		// servers last, they are stopped first and drained while redis is still open
+		sctx.WithComponent(ginc.NewGin("gin")),
 	)
 }
 
@@ -55,18 +56,13 @@ var rootCmd = &cobra.Command{
 		redis := redisc.GetRedis()
 
 		router := ginComp.GetRouter()
-		// middlewares
+		// middlewares, requests are traced (GIN_TRACING_ENABLED) and given a request ID by ginc
 		router.Use(
 			middleware.Logger(),
-			middleware.AllowCORS(),
-			middleware.Recovery(serviceCtx),
-			otelgin.Middleware(serviceContextName),
+			middleware.Recovery(),
 		)
 
-		// health check
-		router.GET("/health", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
-		})
+		// health checks are served by ginc at /livez and /readyz
 
 		// test redis connection (legacy endpoint)
 		router.GET("/redis/test", func(c *gin.Context) {
@@ -92,39 +88,22 @@ var rootCmd = &cobra.Command{
 			c.JSON(http.StatusOK, gin.H{"result": result})
 		})
 
-		// Cache service using composer pattern
-		cacheApiService := composer.ComposeCacheApiService(serviceCtx)
-		cache := router.Group("/cache")
-		{
-			cache.POST("", cacheApiService.SetCacheHandler())           // Set cache
-			cache.GET("/:key", cacheApiService.GetCacheHandler())       // Get cache by key
-			cache.DELETE("/:key", cacheApiService.DeleteCacheHandler()) // Delete cache by key
-			cache.HEAD("/:key", cacheApiService.ExistsCacheHandler())   // Check if key exists
-			cache.GET("", cacheApiService.ListKeysHandler())            // List keys (with optional pattern query)
+		// the cache module routes (/cache) are registered by ginc on Start
+		if err := ginComp.Start(); err != nil {
+			slog.Error("start server error", "error", err)
+			panic(err)
 		}
 
-		srv := &http.Server{Addr: fmt.Sprintf(":%d", ginComp.GetPort()), Handler: router}
-
-		go func() {
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				slog.Error("listen error", "error", err)
-			}
-		}()
-
-		slog.Info("server started", "port", ginComp.GetPort())
-
 		// graceful shutdown
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 		<-quit
 		slog.Info("shutting down server...")
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := srv.Shutdown(ctx); err != nil {
+		// components stop in reverse registration order: gin is drained first, then the datastores are closed
+		if err := serviceCtx.Stop(); err != nil {
 			slog.Error("server shutdown error", "error", err)
 		}
-		_ = serviceCtx.Stop()
 		slog.Info("server exited")
 	},
 }
@@ -6,4 +6,5 @@ const (
 	KeyCompGIN   = "gin"
 	KeyCompOtel  = "otel"
 	KeyCompSlog  = "slog"
+	KeyCompCache = "cache"
 )
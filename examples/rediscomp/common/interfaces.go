@@ -14,4 +14,5 @@ type RedisComponent interface {
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
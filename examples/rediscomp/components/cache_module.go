@@ -0,0 +1,60 @@
+package composer
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/ginc"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
+	"github.com/taimaifika/service-context/examples/rediscomp/common"
+	cachebiz "github.com/taimaifika/service-context/examples/rediscomp/services/cache/biz"
+)
+
+// cacheModule is the cache service as a component, ginc registers its routes under /cache on start.
+// Register it after the redis component, it is composed on Activate.
+type cacheModule struct {
+	id            string
+	api           CacheService
+	responseCache *middleware.ResponseCache
+}
+
+func NewCacheModule(id string) *cacheModule {
+	return &cacheModule{id: id}
+}
+
+func (m *cacheModule) ID() string {
+	return m.id
+}
+
+func (m *cacheModule) InitFlags() {}
+
+func (m *cacheModule) Activate(serviceCtx sctx.ServiceContext) error {
+	redisComp := serviceCtx.MustGet(common.KeyCompRedis).(common.RedisComponent)
+
+	// HTTP responses cached in redis, invalidated by the cache biz on writes
+	m.responseCache = middleware.NewResponseCache(middleware.NewRedisCacheStore(redisComp.GetRedis()))
+	m.api = ComposeCacheApiService(serviceCtx, m.responseCache)
+
+	return nil
+}
+
+func (m *cacheModule) Stop() error {
+	return nil
+}
+
+func (m *cacheModule) RouteOptions() ginc.RouteOptions {
+	return ginc.RouteOptions{Prefix: "/cache"}
+}
+
+func (m *cacheModule) RegisterRoutes(cache *gin.RouterGroup) {
+	cache.POST("", m.api.SetCacheHandler()) // Set cache
+	cache.GET("/:key", m.responseCache.Cache(middleware.CacheConfig{
+		TTL:  30 * time.Second,
+		Tags: func(c *gin.Context) []string { return []string{cachebiz.ResponseTag(c.Param("key"))} },
+	}), m.api.GetCacheHandler()) // Get cache by key
+	cache.DELETE("/:key", m.api.DeleteCacheHandler()) // Delete cache by key
+	cache.HEAD("/:key", m.api.ExistsCacheHandler())   // Check if key exists
+	cache.GET("", m.api.ListKeysHandler())            // List keys (with optional pattern query)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	sctx "github.com/taimaifika/service-context"
+	"github.com/taimaifika/service-context/component/ginc/middleware"
 	"github.com/taimaifika/service-context/examples/rediscomp/common"
 	cachebiz "github.com/taimaifika/service-context/examples/rediscomp/services/cache/biz"
 	cacherepo "github.com/taimaifika/service-context/examples/rediscomp/services/cache/repository/redis"
@@ -18,7 +19,8 @@ type CacheService interface {
 	ListKeysHandler() func(*gin.Context)
 }
 
-func ComposeCacheApiService(serviceCtx sctx.ServiceContext) CacheService {
+// ComposeCacheApiService builds the cache API, writes invalidate the responses cached by responseCache.
+func ComposeCacheApiService(serviceCtx sctx.ServiceContext, responseCache *middleware.ResponseCache) CacheService {
 	// load redis client
 	redisComp := serviceCtx.MustGet(common.KeyCompRedis).(common.RedisComponent)
 
@@ -26,7 +28,7 @@ func ComposeCacheApiService(serviceCtx sctx.ServiceContext) CacheService {
 	cacheRepo := cacherepo.NewRedisRepo(redisComp.GetRedis())
 
 	// create business logic
-	biz := cachebiz.NewCacheBiz(cacheRepo)
+	biz := cachebiz.NewCacheBiz(cacheRepo, responseCache)
 
 	// create API service
 	serviceApi := cacheapi.NewCacheApi(serviceCtx, biz)
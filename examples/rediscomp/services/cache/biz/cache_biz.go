@@ -18,12 +18,23 @@ type CacheRepo interface {
 	Keys(ctx context.Context, pattern string) ([]string, error)
 }
 
+// ResponseInvalidator drops the HTTP responses cached for a tag, see middleware.ResponseCache.
+type ResponseInvalidator interface {
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
 type cacheBiz struct {
-	repo CacheRepo
+	repo        CacheRepo
+	invalidator ResponseInvalidator
+}
+
+func NewCacheBiz(repo CacheRepo, invalidator ResponseInvalidator) *cacheBiz {
+	return &cacheBiz{repo: repo, invalidator: invalidator}
 }
 
-func NewCacheBiz(repo CacheRepo) *cacheBiz {
-	return &cacheBiz{repo: repo}
+// ResponseTag is the tag of the cached responses of a key.
+func ResponseTag(key string) string {
+	return "cache-item:" + key
 }
 
 func (b *cacheBiz) SetCache(ctx context.Context, req *entity.SetCacheRequest) error {
@@ -45,7 +56,11 @@ func (b *cacheBiz) SetCache(ctx context.Context, req *entity.SetCacheRequest) er
 		TTL:   req.TTL,
 	}
 
-	return b.repo.Set(ctx, item)
+	if err := b.repo.Set(ctx, item); err != nil {
+		return err
+	}
+
+	return b.invalidator.InvalidateTags(ctx, ResponseTag(req.Key))
 }
 
 func (b *cacheBiz) GetCache(ctx context.Context, key string) (*entity.CacheItem, error) {
@@ -77,7 +92,12 @@ func (b *cacheBiz) DeleteCache(ctx context.Context, key string) (int64, error) {
 		return 0, fmt.Errorf("key cannot be empty")
 	}
 
-	return b.repo.Delete(ctx, key)
+	deleted, err := b.repo.Delete(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, b.invalidator.InvalidateTags(ctx, ResponseTag(key))
 }
 
 func (b *cacheBiz) ExistsCache(ctx context.Context, key string) (bool, error) {
@@ -5,11 +5,11 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
-	"go.opentelemetry.io/otel"
 
 	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
 	"github.com/taimaifika/service-context/examples/scylladbcomp/composer"
@@ -29,10 +29,12 @@ func newServiceCtx() sctx.ServiceContext {
 		sctx.WithName(serviceName),
 		sctx.WithComponent(slogc.NewSlogComponent()),
 		sctx.WithComponent(otelc.NewOtel(common.KeyCompOtel)),
-		sctx.WithComponent(ginc.NewGin(common.KeyCompGin)),
 		sctx.WithComponent(scylladbc.NewScyllaDbComponent(common.KeyCompScylla)),
 
 		sctx.WithComponent(NewConfig()),
+
+		// servers last, they are stopped first and drained while scylla is still open
+		sctx.WithComponent(ginc.NewGin(common.KeyCompGin)),
 	)
 }
 
@@ -51,17 +53,8 @@ var rootCmd = &cobra.Command{
 		ginComp := serviceCtx.MustGet(common.KeyCompGin).(common.GinComponent)
 
 		router := ginComp.GetRouter()
-		router.Use(
-			middleware.AllowCORS(),
-			otelgin.Middleware(
-				serviceCtx.GetName(),
-				otelgin.WithTracerProvider(otel.GetTracerProvider()),
-				otelgin.WithFilter(func(req *http.Request) bool {
-					// Skip tracing for /ping endpoints
-					return req.URL.Path != "/ping"
-				}),
-			),
-		)
+		// requests are traced by ginc (GIN_TRACING_ENABLED), /ping is excluded by GIN_TRACING_EXCLUDE_PATHS
+		router.Use(middleware.ErrorHandler())
 
 		router.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "pong"})
@@ -72,10 +65,19 @@ var rootCmd = &cobra.Command{
 		exampleRoutes(v1, serviceCtx)
 
 		// Start the server
-		if err := router.Run(fmt.Sprintf(":%d", ginComp.GetPort())); err != nil {
+		if err := ginComp.Start(); err != nil {
 			slog.Error("Service start error", "error", err)
 			panic(err)
 		}
+
+		// Graceful shutdown
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		<-quit
+
+		if err := serviceCtx.Stop(); err != nil {
+			slog.Error("Service stop error", "error", err)
+		}
 	},
 }
 
@@ -9,6 +9,7 @@ import (
 type GinComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 type ScyllaComponent interface {
@@ -3,7 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
 	"github.com/taimaifika/service-context/examples/scylladbcomp/services/task/entity"
 
 	"github.com/gin-gonic/gin"
@@ -20,12 +19,12 @@ func (a *api) ScyllaCreatePersonHdl() func(*gin.Context) {
 		var data entity.PersonCreateRequest
 
 		if err := c.ShouldBind(&data); err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		if err := a.biz.ScyllaAddNewPerson(ctx, &data); err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
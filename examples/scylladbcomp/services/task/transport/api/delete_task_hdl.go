@@ -3,8 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
-
 	"github.com/gin-gonic/gin"
 	"github.com/taimaifika/service-context/core"
 	"go.opentelemetry.io/otel"
@@ -19,12 +17,12 @@ func (a *api) ScyllaDeleteTaskHdl() func(*gin.Context) {
 		uid, err := core.FromBase58(c.Param("task-id"))
 
 		if err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		if err := a.biz.ScyllaDeleteTask(ctx, int(uid.GetLocalID())); err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
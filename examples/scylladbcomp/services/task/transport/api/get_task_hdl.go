@@ -3,8 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
-
 	"github.com/gin-gonic/gin"
 	"github.com/taimaifika/service-context/core"
 	"go.opentelemetry.io/otel"
@@ -19,14 +17,14 @@ func (a *api) ScyllaGetTaskHdl() func(*gin.Context) {
 		uid, err := core.FromBase58(c.Param("task-id"))
 
 		if err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		data, err := a.biz.ScyllaGetTaskById(ctx, int(uid.GetLocalID()))
 
 		if err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
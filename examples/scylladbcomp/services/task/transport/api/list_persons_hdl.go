@@ -3,7 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
 	"github.com/taimaifika/service-context/examples/scylladbcomp/services/task/entity"
 
 	"github.com/gin-gonic/gin"
@@ -20,14 +19,14 @@ func (a *api) ScyllaListPersonHdl() func(*gin.Context) {
 		var filter entity.PersonFilter
 
 		if err := c.ShouldBind(&filter); err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		persons, err := a.biz.ScyllaListPersons(ctx, &filter)
 
 		if err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
@@ -3,8 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
-
 	"github.com/taimaifika/service-context/examples/scylladbcomp/services/task/entity"
 
 	"github.com/gin-gonic/gin"
@@ -26,7 +24,7 @@ func (a *api) ScyllaListTaskHdl() func(*gin.Context) {
 		var rp reqParam
 
 		if err := c.ShouldBind(&rp); err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
@@ -35,7 +33,7 @@ func (a *api) ScyllaListTaskHdl() func(*gin.Context) {
 		tasks, err := a.biz.ScyllaListTasks(ctx, &rp.Filter, &rp.Paging)
 
 		if err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
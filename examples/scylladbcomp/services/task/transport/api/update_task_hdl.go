@@ -3,8 +3,6 @@ package api
 import (
 	"net/http"
 
-	"github.com/taimaifika/service-context/examples/scylladbcomp/common"
-
 	"github.com/taimaifika/service-context/examples/scylladbcomp/services/task/entity"
 
 	"github.com/gin-gonic/gin"
@@ -21,19 +19,19 @@ func (a *api) ScyllaUpdateTaskHdl() func(*gin.Context) {
 		uid, err := core.FromBase58(c.Param("task-id"))
 
 		if err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		var data entity.TaskUpdateRequest
 
 		if err := c.ShouldBind(&data); err != nil {
-			common.WriteErrorResponse(c, core.ErrBadRequest.WithError(err.Error()))
+			_ = c.Error(core.ErrBadRequest.WithError(err.Error()))
 			return
 		}
 
 		if err := a.biz.ScyllaUpdateTask(ctx, int(uid.GetLocalID()), &data); err != nil {
-			common.WriteErrorResponse(c, err)
+			_ = c.Error(err)
 			return
 		}
 
@@ -20,6 +20,7 @@ func newServiceCtx() sctx.ServiceContext {
 type GINComponent interface {
 	GetPort() int
 	GetRouter() *gin.Engine
+	Start() error
 }
 
 var outEnvCmd = &cobra.Command{
@@ -1,6 +1,7 @@
 package sctx
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -19,7 +20,8 @@ const (
 // The ID method should return a unique string that identifies the component.
 // The InitFlags method is called before the flags are parsed.
 // The Activate method is called when the service context is loaded.
-// The Stop method is called when the service context is stopped.
+// The Stop method is called when the service context is stopped, in the reverse order of registration,
+// so servers must be registered last.
 // Important, workflow: InitFlags -> Activate -> Stop
 type Component interface {
 	ID() string
@@ -28,6 +30,24 @@ type Component interface {
 	Stop() error
 }
 
+// HealthChecker can be implemented by a component to report the health of its dependencies (database, cache...).
+// It is used by readiness probes, a nil error means the component is healthy.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// ComponentLister is implemented by the service context of NewServiceContext. It is kept out of
+// ServiceContext so other implementations and mocks do not have to provide it.
+type ComponentLister interface {
+	Components() []Component
+}
+
+// HealthReporter is implemented by the service context of NewServiceContext, it runs the health checks
+// of the components for the readiness probes. Like ComponentLister, it is kept out of ServiceContext.
+type HealthReporter interface {
+	HealthCheck(ctx context.Context) map[string]error
+}
+
 type ServiceContext interface {
 	Load() error
 	MustGet(id string) interface{}
@@ -91,6 +111,12 @@ func (s *serviceCtx) MustGet(id string) interface{} {
 	return c
 }
 
+// Components returns the components in the order of registration,
+// ex: to discover the ones implementing an optional interface after Load.
+func (s *serviceCtx) Components() []Component {
+	return append([]Component(nil), s.components...)
+}
+
 func (s *serviceCtx) Load() error {
 	slog.Info("Service context is loading...")
 
@@ -103,9 +129,11 @@ func (s *serviceCtx) Load() error {
 	return nil
 }
 
+// Stop stops components in the reverse order of registration. Register servers (ginc, httpserverc, grpcc)
+// after the components they depend on, so they are drained before the datastores are closed.
 func (s *serviceCtx) Stop() error {
 	slog.Info("Stopping service context")
-	for i := range s.components {
+	for i := len(s.components) - 1; i >= 0; i-- {
 		if err := s.components[i].Stop(); err != nil {
 			return err
 		}
@@ -116,6 +144,20 @@ func (s *serviceCtx) Stop() error {
 	return nil
 }
 
+// HealthCheck runs the health check of every component implementing HealthChecker.
+// The result is keyed by component ID, a nil value means the component is healthy.
+func (s *serviceCtx) HealthCheck(ctx context.Context) map[string]error {
+	result := make(map[string]error)
+
+	for _, c := range s.components {
+		if hc, ok := c.(HealthChecker); ok {
+			result[c.ID()] = hc.HealthCheck(ctx)
+		}
+	}
+
+	return result
+}
+
 func (s *serviceCtx) GetName() string { return s.name }
 func (s *serviceCtx) EnvName() string { return s.env }
 func (s *serviceCtx) OutEnv()         { s.cmdLine.GetSampleEnvs() }